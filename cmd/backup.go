@@ -0,0 +1,42 @@
+package cmd
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"github.com/spf13/cobra"
+	"go-time/db"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+func BackupCmd(database *sql.DB, backupDir string) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "backup [file]",
+		Short: "Back up the database",
+		Long:  `Back up the database using SQLite's online backup API. Without a file argument the backup is written to the configured backup directory.`,
+		Args:  cobra.MaximumNArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			ctx := context.Background()
+
+			dest := filepath.Join(backupDir, db.BackupFileName(time.Now()))
+			if len(args) == 1 {
+				dest = args[0]
+			}
+
+			if err := os.MkdirAll(filepath.Dir(dest), os.ModePerm); err != nil {
+				fmt.Println("Error creating backup directory:", err)
+				return
+			}
+
+			if err := db.Backup(ctx, database, dest); err != nil {
+				fmt.Println("Error backing up database:", err)
+				return
+			}
+			fmt.Println("Database backed up to:", dest)
+		},
+	}
+
+	return cmd
+}
@@ -0,0 +1,123 @@
+package cmd
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"github.com/spf13/cobra"
+	"go-time/pkgs/entry"
+	"go-time/pkgs/util"
+)
+
+// bulkPreview is how many of the matching entries are listed before asking
+// to change them.
+const bulkPreview = 10
+
+func BulkCmd(db *sql.DB) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "bulk",
+		Short: "Change all time entries matching a filter at once",
+		Long: `Tag, untag, rename, move or delete all time entries selected by --from, --to, --tag, --name and
+--project at once.
+The matching entries are listed before anything changes, and all of them change in one transaction.`,
+	}
+
+	cmd.AddCommand(
+		bulkCmd(db, "tag [tag...]", "Add tags to the matching entries", "Tag", cobra.MinimumNArgs(1),
+			func(ctx context.Context, ids []int, args []string) (int, error) {
+				return entry.TagEntries(ctx, db, ids, args)
+			}),
+		bulkCmd(db, "untag [tag...]", "Remove tags from the matching entries", "Untag", cobra.MinimumNArgs(1),
+			func(ctx context.Context, ids []int, args []string) (int, error) {
+				return entry.UntagEntries(ctx, db, ids, args)
+			}),
+		bulkCmd(db, "rename [name]", "Rename the matching entries", "Rename", cobra.ExactArgs(1),
+			func(ctx context.Context, ids []int, args []string) (int, error) {
+				return entry.RenameEntries(ctx, db, ids, args[0])
+			}),
+		bulkCmd(db, "move-project [project]", "Move the matching entries to a project, or out of any with \"\"", "Move", cobra.ExactArgs(1),
+			func(ctx context.Context, ids []int, args []string) (int, error) {
+				return entry.MoveEntries(ctx, db, ids, args[0])
+			}),
+		bulkCmd(db, "delete", "Delete the matching entries", "Delete", cobra.NoArgs,
+			func(ctx context.Context, ids []int, args []string) (int, error) {
+				return entry.DeleteEntries(ctx, db, ids)
+			}),
+	)
+
+	return cmd
+}
+
+// bulkCmd builds a bulk subcommand that applies apply to the IDs of the
+// entries matching its filter flags, with arguments checked by args.
+func bulkCmd(db *sql.DB, use, short, verb string, args cobra.PositionalArgs,
+	apply func(ctx context.Context, ids []int, args []string) (int, error)) *cobra.Command {
+	var filterFlags entryFilterFlags
+	var yes bool
+
+	cmd := &cobra.Command{
+		Use:   use,
+		Short: short,
+		Args:  args,
+		Run: func(cmd *cobra.Command, args []string) {
+			ctx := context.Background()
+
+			filter, err := filterFlags.filter()
+			if err != nil {
+				fmt.Println("Error:", err)
+				return
+			}
+			if filter.IsZero() {
+				fmt.Println("Error: select the entries with --from, --to, --tag, --name or --project")
+				return
+			}
+
+			entries, err := entry.ReadEntriesMatching(ctx, db, filter)
+			if err != nil {
+				fmt.Println("Error reading time entries:", err)
+				return
+			}
+			if len(entries) == 0 {
+				fmt.Println("No time entries match.")
+				return
+			}
+
+			fmt.Printf("%d time entries match:\n", len(entries))
+			for i, e := range entries {
+				if i == bulkPreview {
+					fmt.Printf("  ... and %d more\n", len(entries)-bulkPreview)
+					break
+				}
+				fmt.Printf("  %-4d %-20s %s  %s\n", e.ID, e.Name, util.FormatTime(e.StartTime), util.FormatDuration(e.Duration()))
+			}
+
+			if !yes {
+				confirmed, err := entry.ConfirmBulk(verb, len(entries))
+				if err != nil {
+					fmt.Println("Error:", err)
+					return
+				}
+				if !confirmed {
+					fmt.Println("Nothing changed.")
+					return
+				}
+			}
+
+			ids := make([]int, len(entries))
+			for i, e := range entries {
+				ids[i] = e.ID
+			}
+			changed, err := apply(ctx, ids, args)
+			if err != nil {
+				fmt.Println("Error changing time entries, nothing changed:", err)
+				return
+			}
+			fmt.Printf("Changed %d of %d time entries.\n", changed, len(entries))
+		},
+	}
+
+	filterFlags.register(cmd)
+	cmd.Flags().BoolVarP(&yes, "yes", "y", false, "Change the entries without asking")
+
+	return cmd
+}
@@ -0,0 +1,140 @@
+package cmd
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"github.com/spf13/cobra"
+	"go-time/pkgs/entry"
+	"go-time/pkgs/util"
+	"time"
+)
+
+func CheckCmd(db *sql.DB) *cobra.Command {
+	var from, to, fix string
+	var minGap time.Duration
+
+	cmd := &cobra.Command{
+		Use:   "check",
+		Short: "Find overlapping entries and gaps",
+		Long: `Find entries that track the same time and untracked gaps of at least --min-gap between entries
+of a day in a date range. With --fix, overlaps are resolved by trimming the earlier entry to end where
+the later one starts, or by merging both into one entry.`,
+		Run: func(cmd *cobra.Command, args []string) {
+			ctx := context.Background()
+
+			if fix != "" && fix != "trim" && fix != "merge" {
+				fmt.Printf("Error: unknown fix %q, expected trim or merge\n", fix)
+				return
+			}
+
+			now := time.Now()
+			toDay := util.StartOfDay(now)
+			fromDay := toDay.AddDate(0, 0, -6)
+
+			var err error
+			if from != "" {
+				if fromDay, err = parseDay(from); err != nil {
+					fmt.Println("Error parsing --from:", err)
+					return
+				}
+			}
+			if to != "" {
+				if toDay, err = parseDay(to); err != nil {
+					fmt.Println("Error parsing --to:", err)
+					return
+				}
+			}
+			if toDay.Before(fromDay) {
+				fmt.Println("Error: --to cannot be before --from")
+				return
+			}
+			end := toDay.AddDate(0, 0, 1)
+
+			if fix != "" {
+				fixOverlaps(ctx, db, fromDay, end, fix)
+			}
+
+			entries, err := entry.ReadEntriesBetween(ctx, db, fromDay, end)
+			if err != nil {
+				fmt.Println("Error reading time entries:", err)
+				return
+			}
+
+			overlaps := entry.FindOverlaps(entries)
+			gaps := entry.FindGaps(entries, fromDay, toDay, minGap)
+			if len(overlaps) == 0 && len(gaps) == 0 {
+				fmt.Println("No overlaps or gaps found.")
+				return
+			}
+
+			if len(overlaps) > 0 {
+				fmt.Println("Overlaps:")
+				for _, o := range overlaps {
+					fmt.Printf("  %s %8s  #%d %s / #%d %s\n", period(o.Start, o.End),
+						util.FormatDuration(o.Duration()), o.First.ID, o.First.Name, o.Second.ID, o.Second.Name)
+				}
+			}
+			if len(gaps) > 0 {
+				fmt.Println("Gaps:")
+				for _, g := range gaps {
+					fmt.Printf("  %s %8s\n", period(g.Start, g.End), util.FormatDuration(g.Duration()))
+				}
+			}
+		},
+	}
+
+	cmd.Flags().StringVar(&from, "from", "", "First day to check (YYYY-MM-DD, default 6 days ago)")
+	cmd.Flags().StringVar(&to, "to", "", "Last day to check (YYYY-MM-DD, default today)")
+	cmd.Flags().DurationVar(&minGap, "min-gap", 15*time.Minute, "Shortest untracked time between entries reported as a gap")
+	cmd.Flags().StringVar(&fix, "fix", "", "Resolve overlaps by trimming or merging the entries (trim or merge)")
+
+	return cmd
+}
+
+// fixOverlaps resolves the overlaps between entries from start to end one at
+// a time, since every fix changes the entries involved. Overlaps that cannot
+// be fixed are reported and left as they are.
+func fixOverlaps(ctx context.Context, db *sql.DB, start, end time.Time, fix string) {
+	failed := make(map[[2]int]bool)
+	for {
+		entries, err := entry.ReadEntriesBetween(ctx, db, start, end)
+		if err != nil {
+			fmt.Println("Error reading time entries:", err)
+			return
+		}
+
+		var next *entry.Overlap
+		for _, o := range entry.FindOverlaps(entries) {
+			if !failed[[2]int{o.First.ID, o.Second.ID}] {
+				next = &o
+				break
+			}
+		}
+		if next == nil {
+			return
+		}
+
+		if fix == "trim" {
+			err = entry.TrimOverlap(ctx, db, *next)
+		} else {
+			err = entry.MergeEntries(ctx, db, next.First.ID, next.Second.ID)
+		}
+		if err != nil {
+			fmt.Printf("Could not %s entries %d and %d: %v\n", fix, next.First.ID, next.Second.ID, err)
+			failed[[2]int{next.First.ID, next.Second.ID}] = true
+			continue
+		}
+		if fix == "trim" {
+			fmt.Printf("Trimmed entry %d to end at %s\n", next.First.ID, util.FormatTime(next.Second.StartTime))
+		} else {
+			fmt.Printf("Merged entry %d into entry %d\n", next.Second.ID, next.First.ID)
+		}
+	}
+}
+
+// period formats a period within a day as "2006-01-02  15:04-15:04".
+func period(start, end time.Time) string {
+	loc := util.Location()
+	return start.In(loc).Format("2006-01-02  15:04") + "-" + end.In(loc).Format("15:04")
+}
@@ -0,0 +1,144 @@
+package cmd
+
+import (
+	"fmt"
+	"github.com/spf13/cobra"
+	"go-time/pkgs/config"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+func ConfigCmd(cfg *config.Config) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "config",
+		Short: "View and change configuration",
+		Long: `View and change configuration. Settings are layered: defaults, then the config file, then
+GO_TIME_* environment variables, then command line flags.
+
+Available keys: ` + strings.Join(config.Keys(), ", "),
+	}
+
+	cmd.AddCommand(
+		configGetCmd(cfg),
+		configSetCmd(cfg),
+		configListCmd(cfg),
+		configEditCmd(cfg),
+		configPathCmd(cfg),
+	)
+
+	return cmd
+}
+
+func configGetCmd(cfg *config.Config) *cobra.Command {
+	return &cobra.Command{
+		Use:       "get [key]",
+		Short:     "Print the effective value of a setting",
+		Args:      cobra.ExactArgs(1),
+		ValidArgs: config.Keys(),
+		Run: func(cmd *cobra.Command, args []string) {
+			value, err := cfg.Get(args[0])
+			if err != nil {
+				fmt.Println("Error reading config:", err)
+				return
+			}
+			fmt.Println(value)
+		},
+	}
+}
+
+func configSetCmd(cfg *config.Config) *cobra.Command {
+	return &cobra.Command{
+		Use:   "set [key] [value]",
+		Short: "Store a setting in the config file",
+		Args:  cobra.ExactArgs(2),
+		Run: func(cmd *cobra.Command, args []string) {
+			if err := cfg.Set(args[0], args[1]); err != nil {
+				fmt.Println("Error updating config:", err)
+				return
+			}
+			fmt.Printf("%s set to %q\n", args[0], args[1])
+		},
+	}
+}
+
+func configListCmd(cfg *config.Config) *cobra.Command {
+	return &cobra.Command{
+		Use:   "list",
+		Short: "List all settings with their values and sources",
+		Args:  cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			values := cfg.List()
+			width := 0
+			for _, v := range values {
+				width = max(width, len(v.Key))
+			}
+			for _, v := range values {
+				fmt.Printf("%-*s = %-30s (%s)\n", width, v.Key, v.Value, v.Source)
+			}
+		},
+	}
+}
+
+func configEditCmd(cfg *config.Config) *cobra.Command {
+	return &cobra.Command{
+		Use:   "edit",
+		Short: "Open the config file in $VISUAL or $EDITOR",
+		Args:  cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			if _, err := os.Stat(cfg.ConfigFile); os.IsNotExist(err) {
+				if err := cfg.SaveWithComments(); err != nil {
+					fmt.Println("Error creating config file:", err)
+					return
+				}
+			}
+
+			editor := os.Getenv("VISUAL")
+			if editor == "" {
+				editor = os.Getenv("EDITOR")
+			}
+			if editor == "" {
+				editor = "vi"
+			}
+
+			parts := strings.Fields(editor)
+			editCmd := exec.Command(parts[0], append(parts[1:], cfg.ConfigFile)...)
+			editCmd.Stdin = os.Stdin
+			editCmd.Stdout = os.Stdout
+			editCmd.Stderr = os.Stderr
+			if err := editCmd.Run(); err != nil {
+				fmt.Println("Error running editor:", err)
+				return
+			}
+
+			if err := cfg.Reload(); err != nil {
+				fmt.Println("Config file is invalid:", err)
+				return
+			}
+			for _, warning := range cfg.Warnings {
+				fmt.Println("Warning:", warning)
+			}
+		},
+	}
+}
+
+func configPathCmd(cfg *config.Config) *cobra.Command {
+	var data bool
+
+	cmd := &cobra.Command{
+		Use:   "path",
+		Short: "Print the path of the config file",
+		Args:  cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			if data {
+				fmt.Println(cfg.DataDir)
+				return
+			}
+			fmt.Println(cfg.ConfigFile)
+		},
+	}
+
+	cmd.Flags().BoolVar(&data, "data", false, "Print the data directory instead")
+
+	return cmd
+}
@@ -6,21 +6,42 @@ import (
 	"fmt"
 	"github.com/spf13/cobra"
 	"go-time/pkgs/entry"
+	"go-time/pkgs/util"
 )
 
 func EditCmd(db *sql.DB) *cobra.Command {
 	var id int
-	var name, description string
+	var name, description, start, end string
 	var tags []string
 
 	cmd := &cobra.Command{
 		Use:   "edit",
 		Short: "Edit an existing time entry",
-		Long:  `Edit an existing time entry by specifying its ID, name, and description.`,
+		Long:  `Edit an existing time entry by specifying its ID, name, description, and optionally its start and end times.`,
 		Run: func(cmd *cobra.Command, args []string) {
 			ctx := context.Background()
 
-			err := entry.EditEntry(ctx, db, id, name, description, tags)
+			existing, err := entry.GetEntry(ctx, db, id)
+			if err != nil {
+				fmt.Println("Error reading time entry:", err)
+				return
+			}
+
+			startTime, endTime := existing.StartTime, existing.EndTime
+			if start != "" {
+				if startTime, err = util.ParseTime(start); err != nil {
+					fmt.Println("Error parsing start time:", err)
+					return
+				}
+			}
+			if end != "" {
+				if endTime, err = util.ParseTime(end); err != nil {
+					fmt.Println("Error parsing end time:", err)
+					return
+				}
+			}
+
+			err = entry.EditEntry(ctx, db, id, name, description, startTime, endTime, tags)
 			if err != nil {
 				fmt.Println("Error editing time entry:", err)
 				return
@@ -35,6 +56,8 @@ func EditCmd(db *sql.DB) *cobra.Command {
 	cmd.MarkFlagRequired("name")
 	cmd.Flags().StringVarP(&description, "description", "d", "", "Description of the time entry")
 	cmd.MarkFlagRequired("description")
+	cmd.Flags().StringVar(&start, "start", "", "Start time of the time entry (YYYY-MM-DD HH:MM:SS)")
+	cmd.Flags().StringVar(&end, "end", "", "End time of the time entry (YYYY-MM-DD HH:MM:SS)")
 	cmd.Flags().StringArrayVarP(&tags, "tags", "t", nil, "Tags for the time entry")
 
 	return cmd
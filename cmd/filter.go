@@ -0,0 +1,46 @@
+package cmd
+
+import (
+	"fmt"
+	"github.com/spf13/cobra"
+	"go-time/pkgs/entry"
+)
+
+// entryFilterFlags are the flags that select entries, shared by read and
+// bulk.
+type entryFilterFlags struct {
+	from, to, tag, name, project string
+}
+
+func (f *entryFilterFlags) register(cmd *cobra.Command) {
+	flags := cmd.Flags()
+	flags.StringVar(&f.from, "from", "", "Only entries on or after this day (YYYY-MM-DD)")
+	flags.StringVar(&f.to, "to", "", "Only entries on or before this day (YYYY-MM-DD)")
+	flags.StringVar(&f.tag, "tag", "", "Only entries with this tag")
+	flags.StringVar(&f.name, "name", "", "Only entries whose name matches this pattern, * matches any text")
+	flags.StringVar(&f.project, "project", "", "Only entries in this project")
+}
+
+// filter parses the flags into an entry filter. The range covers the whole
+// days given.
+func (f entryFilterFlags) filter() (entry.Filter, error) {
+	result := entry.Filter{Tag: f.tag, Name: f.name, Project: f.project}
+	if f.from != "" {
+		day, err := parseDay(f.from)
+		if err != nil {
+			return entry.Filter{}, fmt.Errorf("error parsing --from: %w", err)
+		}
+		result.From = day
+	}
+	if f.to != "" {
+		day, err := parseDay(f.to)
+		if err != nil {
+			return entry.Filter{}, fmt.Errorf("error parsing --to: %w", err)
+		}
+		result.To = day.AddDate(0, 0, 1)
+	}
+	if !result.From.IsZero() && !result.To.IsZero() && !result.To.After(result.From) {
+		return entry.Filter{}, fmt.Errorf("--to cannot be before --from")
+	}
+	return result, nil
+}
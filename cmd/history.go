@@ -0,0 +1,60 @@
+package cmd
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"github.com/spf13/cobra"
+	"go-time/pkgs/entry"
+	"go-time/pkgs/util"
+)
+
+func HistoryCmd(db *sql.DB) *cobra.Command {
+	var id int
+
+	cmd := &cobra.Command{
+		Use:   "history",
+		Short: "Show the change history of a time entry",
+		Long:  `Show a chronological list of changes made to a time entry's name, description, times and tags.`,
+		Run: func(cmd *cobra.Command, args []string) {
+			ctx := context.Background()
+
+			records, err := entry.ReadHistory(ctx, db, id)
+			if err != nil {
+				fmt.Println("Error reading entry history:", err)
+				return
+			}
+			if len(records) == 0 {
+				fmt.Printf("No history recorded for entry %d.\n", id)
+				return
+			}
+
+			var prev *entry.HistoryRecord
+			for i, record := range records {
+				fmt.Printf("%s  %s\n", util.FormatTime(record.ChangedAt), record.Action)
+
+				changes := record.Diff(prev)
+				if prev == nil && record.Action != entry.ActionCreate {
+					// The entry predates the history, so its earlier values
+					// are unknown.
+					fmt.Println("    (first recorded change, earlier values unknown)")
+				} else if len(changes) == 0 && record.Action == entry.ActionEdit {
+					fmt.Println("    (no changes)")
+				}
+				for _, change := range changes {
+					if prev == nil {
+						fmt.Printf("    %-12s %q\n", change.Field+":", change.To)
+					} else {
+						fmt.Printf("    %-12s %q -> %q\n", change.Field+":", change.From, change.To)
+					}
+				}
+				prev = &records[i]
+			}
+		},
+	}
+
+	cmd.Flags().IntVarP(&id, "id", "i", 0, "ID of the time entry")
+	cmd.MarkFlagRequired("id")
+
+	return cmd
+}
@@ -0,0 +1,44 @@
+package cmd
+
+import (
+	"context"
+	"database/sql"
+	"github.com/spf13/cobra"
+	"go-time/pkgs/timer"
+	"go-time/pkgs/util"
+	"log"
+	"strings"
+)
+
+func LapCmd(db *sql.DB) *cobra.Command {
+	var taskName string
+
+	cmd := &cobra.Command{
+		Use:   "lap [note]",
+		Short: "Mark a lap with an optional note on a running timer",
+		Long: `Mark the end of a phase of the running timer for a task without stopping it. The note and time of every
+lap are listed in the description of the entry recorded when the timer is stopped, or with stop --split
+each lap becomes an entry of its own.`,
+		Args: cobra.MaximumNArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			ctx := context.Background()
+
+			if taskName == "" {
+				log.Println("Task name is required. Use the --name flag to specify the task name.")
+				return
+			}
+
+			lap, err := timer.AddLap(ctx, db, taskName, strings.Join(args, " "))
+			if err != nil {
+				log.Printf("Error marking lap: %v", err)
+				return
+			}
+			log.Printf("Lap marked for task: %s at %s", taskName, util.FormatTime(lap.Time))
+		},
+	}
+
+	cmd.Flags().StringVarP(&taskName, "name", "n", "", "Name of the task")
+	cmd.MarkFlagRequired("name")
+
+	return cmd
+}
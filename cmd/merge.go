@@ -0,0 +1,34 @@
+package cmd
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"github.com/spf13/cobra"
+	"go-time/pkgs/entry"
+)
+
+func MergeCmd(db *sql.DB) *cobra.Command {
+	var ids []int
+
+	cmd := &cobra.Command{
+		Use:   "merge",
+		Short: "Merge time entries into one",
+		Long: `Merge two or more time entries into the first one given. It then spans all of them, lists their
+descriptions and has all their tags, and the other entries are deleted.`,
+		Run: func(cmd *cobra.Command, args []string) {
+			ctx := context.Background()
+
+			if err := entry.MergeEntries(ctx, db, ids...); err != nil {
+				fmt.Println("Error merging time entries:", err)
+				return
+			}
+			fmt.Printf("Merged %d time entries into entry %d.\n", len(ids), ids[0])
+		},
+	}
+
+	cmd.Flags().IntSliceVarP(&ids, "id", "i", nil, "IDs of the time entries to merge, e.g. -i 3,4")
+	cmd.MarkFlagRequired("id")
+
+	return cmd
+}
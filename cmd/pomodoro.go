@@ -0,0 +1,43 @@
+package cmd
+
+import (
+	"database/sql"
+	"fmt"
+	"github.com/spf13/cobra"
+	"go-time/pkgs/config"
+	"go-time/pkgs/pomodoro"
+)
+
+func PomodoroCmd(db *sql.DB, settings config.AppConfig) *cobra.Command {
+	var taskName string
+	var tags []string
+	defaults := pomodoro.SettingsFrom(settings)
+	s := defaults
+
+	cmd := &cobra.Command{
+		Use:   "pomodoro",
+		Short: "Run pomodoro work and break cycles for a task",
+		Long: `Count down alternating work intervals and breaks for a task, taking a long break after every few work
+intervals. Each work interval is recorded as an entry with the given tags and the terminal bell rings when a
+phase ends. Press n to skip to the next phase and q to stop; a work interval cut short is recorded up to then.`,
+		Run: func(cmd *cobra.Command, args []string) {
+			if err := s.Validate(); err != nil {
+				fmt.Println("Error:", err)
+				return
+			}
+			if err := pomodoro.Run(db, pomodoro.NewSession(taskName, tags, s)); err != nil {
+				fmt.Println("Error running pomodoro:", err)
+			}
+		},
+	}
+
+	cmd.Flags().StringVarP(&taskName, "name", "n", "", "Name of the task")
+	cmd.MarkFlagRequired("name")
+	cmd.Flags().StringArrayVarP(&tags, "tags", "t", nil, "Tags for the recorded entries")
+	cmd.Flags().DurationVar(&s.Work, "work", defaults.Work, "Length of a work interval")
+	cmd.Flags().DurationVar(&s.ShortBreak, "break", defaults.ShortBreak, "Length of a short break")
+	cmd.Flags().DurationVar(&s.LongBreak, "long-break", defaults.LongBreak, "Length of a long break")
+	cmd.Flags().IntVar(&s.Cycles, "cycles", defaults.Cycles, "Number of work intervals before a long break")
+
+	return cmd
+}
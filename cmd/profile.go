@@ -0,0 +1,122 @@
+package cmd
+
+import (
+	"fmt"
+	"github.com/spf13/cobra"
+	"go-time/pkgs/config"
+)
+
+func ProfileCmd(cfg *config.Config, active string) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "profile",
+		Short: "Manage profiles with separate databases",
+		Long: `Manage named profiles. Each profile has its own database and can override any setting of the config file,
+such as timezone or backup_retention, falling back to the top-level value for the others. Select one with --profile,
+the GO_TIME_PROFILE environment variable, or 'profile use'.`,
+	}
+
+	cmd.AddCommand(
+		profileListCmd(cfg, active),
+		profileUseCmd(cfg),
+		profileCreateCmd(cfg),
+		profileSetCmd(cfg),
+	)
+
+	return cmd
+}
+
+func profileListCmd(cfg *config.Config, active string) *cobra.Command {
+	return &cobra.Command{
+		Use:   "list",
+		Short: "List all profiles",
+		Args:  cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			for _, name := range cfg.ProfileNames() {
+				marker := " "
+				if name == active {
+					marker = "*"
+				}
+				settings, err := cfg.ProfileSettings(name)
+				if err != nil {
+					fmt.Println("Error reading profile:", err)
+					continue
+				}
+				fmt.Printf("%s %-20s %s\n", marker, name, settings.DBPath)
+			}
+		},
+	}
+}
+
+func profileUseCmd(cfg *config.Config) *cobra.Command {
+	return &cobra.Command{
+		Use:   "use [name]",
+		Short: "Set the default profile",
+		Args:  cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			if err := cfg.UseProfile(args[0]); err != nil {
+				fmt.Println("Error switching profile:", err)
+				return
+			}
+			fmt.Println("Default profile set to:", args[0])
+		},
+	}
+}
+
+func profileCreateCmd(cfg *config.Config) *cobra.Command {
+	var dbPath, commandMode, backupDir string
+	var values map[string]string
+
+	cmd := &cobra.Command{
+		Use:     "create [name]",
+		Short:   "Create a new profile",
+		Example: `  go-time profile create work --db work.db --set timezone=Europe/Berlin --set backup_retention=14`,
+		Args:    cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			name := args[0]
+			profile := config.Profile{}
+			for key, value := range values {
+				if err := profile.Set(key, value); err != nil {
+					fmt.Println("Error creating profile:", err)
+					return
+				}
+			}
+			for key, value := range map[string]string{"db_path": dbPath, "command_mode": commandMode, "backup_dir": backupDir} {
+				if value == "" {
+					continue
+				}
+				if err := profile.Set(key, value); err != nil {
+					fmt.Println("Error creating profile:", err)
+					return
+				}
+			}
+
+			if err := cfg.AddProfile(name, profile); err != nil {
+				fmt.Println("Error creating profile:", err)
+				return
+			}
+			fmt.Println("Profile created:", name)
+		},
+	}
+
+	cmd.Flags().StringVar(&dbPath, "db", "", "Path to the profile's SQLite database file (default <name>.db)")
+	cmd.Flags().StringVar(&commandMode, "command-mode", "", "Mode in which the application runs for this profile (cli, tui, help)")
+	cmd.Flags().StringVar(&backupDir, "backup-dir", "", "Directory for the profile's automatic backups")
+	cmd.Flags().StringToStringVar(&values, "set", nil, "Override a setting for the profile (key=value, may be repeated)")
+
+	return cmd
+}
+
+func profileSetCmd(cfg *config.Config) *cobra.Command {
+	return &cobra.Command{
+		Use:   "set [name] [key] [value]",
+		Short: "Override a setting for a profile",
+		Args:  cobra.ExactArgs(3),
+		Run: func(cmd *cobra.Command, args []string) {
+			if err := cfg.SetProfile(args[0], args[1], args[2]); err != nil {
+				fmt.Println("Error setting profile value:", err)
+				return
+			}
+			fmt.Printf("%s set to %q for profile %s\n", args[1], args[2], args[0])
+		},
+	}
+}
@@ -5,27 +5,36 @@ import (
 	"database/sql"
 	"fmt"
 	"github.com/spf13/cobra"
+	"go-time/pkgs/config"
 	"go-time/pkgs/entry"
 	"go-time/pkgs/timer"
+	"go-time/pkgs/util"
 	"strings"
 	"time"
 )
 
-func ReadCmd(db *sql.DB) *cobra.Command {
+func ReadCmd(db *sql.DB, settings config.AppConfig) *cobra.Command {
 	var listType string
+	var filterFlags entryFilterFlags
 
 	cmd := &cobra.Command{
 		Use:   "read",
 		Short: "List all active timers or time entries",
-		Long:  `Read command is used to list all active timers or time entries. Use the --type flag to specify 'timers' or 'entries'.`,
+		Long: `Read command is used to list all active timers or time entries. Use the --type flag to specify 'timers' or 'entries'.
+Entries can be narrowed down by day, tag, name and project. Timers running for longer than max_timer_hours are flagged as suspicious.`,
 		Run: func(cmd *cobra.Command, args []string) {
 			ctx := context.Background()
 
 			switch listType {
 			case "entries":
-				readEntries(ctx, db)
+				filter, err := filterFlags.filter()
+				if err != nil {
+					fmt.Println("Error:", err)
+					return
+				}
+				readEntries(ctx, db, filter)
 			case "timers":
-				readTimers(ctx, db)
+				readTimers(ctx, db, time.Duration(settings.MaxTimerHours)*time.Hour)
 			default:
 				fmt.Println("Invalid type. Please specify 'entries' or 'timers' using the --type flag.")
 			}
@@ -33,12 +42,13 @@ func ReadCmd(db *sql.DB) *cobra.Command {
 	}
 
 	cmd.Flags().StringVarP(&listType, "type", "t", "timers", "Specify 'entries' or 'timers' to list")
+	filterFlags.register(cmd)
 
 	return cmd
 }
 
-func readEntries(ctx context.Context, db *sql.DB) {
-	entries, err := entry.ReadEntries(ctx, db)
+func readEntries(ctx context.Context, db *sql.DB, filter entry.Filter) {
+	entries, err := entry.ReadEntriesMatching(ctx, db, filter)
 	if err != nil {
 		fmt.Println("Error listing time entries:", err)
 		return
@@ -70,13 +80,14 @@ func readEntries(ctx context.Context, db *sql.DB) {
 	idWidth := 4
 	nameWidth := 20
 
+	projectWidth := 15
 	timeWidth := 25
 	tagsWidth := 20
 
-	headerFormat := fmt.Sprintf("%%-%ds | %%-%ds | %%-%ds | %%-%ds | %%-%ds\n", idWidth, nameWidth, tagsWidth, timeWidth, timeWidth)
-	rowFormat := fmt.Sprintf("%%-%dd | %%-%ds | %%-%ds | %%-%ds | %%-%ds\n", idWidth, nameWidth, tagsWidth, timeWidth, timeWidth)
+	headerFormat := fmt.Sprintf("%%-%ds | %%-%ds | %%-%ds | %%-%ds | %%-%ds | %%-%ds\n", idWidth, nameWidth, projectWidth, tagsWidth, timeWidth, timeWidth)
+	rowFormat := fmt.Sprintf("%%-%dd | %%-%ds | %%-%ds | %%-%ds | %%-%ds | %%-%ds\n", idWidth, nameWidth, projectWidth, tagsWidth, timeWidth, timeWidth)
 
-	fmt.Printf(headerFormat, "ID", "Name", "Tags", "Start Time", "End Time")
+	fmt.Printf(headerFormat, "ID", "Name", "Project", "Tags", "Start Time", "End Time")
 	for _, entry := range entries {
 		tags, err := getTagsForEntry(entry.ID)
 		if err != nil {
@@ -84,11 +95,11 @@ func readEntries(ctx context.Context, db *sql.DB) {
 			continue
 		}
 		tagStr := strings.Join(tags, ", ")
-		fmt.Printf(rowFormat, entry.ID, entry.Name, tagStr, entry.StartTime.Format(time.RFC3339), entry.EndTime.Format(time.RFC3339))
+		fmt.Printf(rowFormat, entry.ID, entry.Name, entry.Project.String, tagStr, entry.StartTime.In(util.Location()).Format(time.RFC3339), entry.EndTime.In(util.Location()).Format(time.RFC3339))
 	}
 }
 
-func readTimers(ctx context.Context, db *sql.DB) {
+func readTimers(ctx context.Context, db *sql.DB, limit time.Duration) {
 	timers, err := timer.ReadTimers(ctx, db)
 	if err != nil {
 		fmt.Println("Error listing time entries:", err)
@@ -126,10 +137,13 @@ func readTimers(ctx context.Context, db *sql.DB) {
 	timeWidth := 25
 	tagsWidth := 20 // Adjust based on expected tag length
 
-	headerFormat := fmt.Sprintf("%%-%ds | %%-%ds | %%-%ds | %%-%ds \n", idWidth, nameWidth, timeWidth, tagsWidth)
-	rowFormat := fmt.Sprintf("%%-%dd | %%-%ds | %%-%ds | %%-%ds \n", idWidth, nameWidth, timeWidth, tagsWidth)
+	headerFormat := fmt.Sprintf("%%-%ds | %%-%ds | %%-%ds | %%-%ds | %%s\n", idWidth, nameWidth, timeWidth, tagsWidth)
+	rowFormat := fmt.Sprintf("%%-%dd | %%-%ds | %%-%ds | %%-%ds | %%s\n", idWidth, nameWidth, timeWidth, tagsWidth)
 
-	fmt.Printf(headerFormat, "ID", "Name", "Tags", "Start Time")
+	fmt.Printf(headerFormat, "ID", "Name", "Tags", "Start Time", "Running")
+
+	now := time.Now()
+	suspicious := 0
 
 	for _, timer := range timers {
 		tags, err := getTagsForTimer(timer.ID)
@@ -138,6 +152,15 @@ func readTimers(ctx context.Context, db *sql.DB) {
 			continue
 		}
 		tagStr := strings.Join(tags, ", ")
-		fmt.Printf(rowFormat, timer.ID, timer.Name, tagStr, timer.StartTime.Format(time.RFC3339))
+		running := util.FormatDuration(now.Sub(timer.StartTime))
+		if timer.Overdue(now, limit) {
+			running += " (suspicious)"
+			suspicious++
+		}
+		fmt.Printf(rowFormat, timer.ID, timer.Name, tagStr, timer.StartTime.In(util.Location()).Format(time.RFC3339), running)
+	}
+	if suspicious > 0 {
+		fmt.Printf("\n%d timer(s) running for longer than %s may have been forgotten. Stop them to enter the real end time.\n",
+			suspicious, util.FormatDuration(limit))
 	}
 }
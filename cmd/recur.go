@@ -0,0 +1,240 @@
+package cmd
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"github.com/spf13/cobra"
+	"go-time/pkgs/recur"
+	"go-time/pkgs/util"
+	"strings"
+	"time"
+)
+
+func RecurCmd(db *sql.DB) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "recur",
+		Short: "Manage recurring entries such as fixed meetings",
+		Long: `Manage rules for entries that recur daily or weekly at the same time of day, and turn their occurrences
+into entries with 'recur apply'.`,
+	}
+
+	cmd.AddCommand(
+		recurAddCmd(db),
+		recurListCmd(db),
+		recurRmCmd(db),
+		recurSkipCmd(db),
+		recurApplyCmd(db),
+	)
+
+	return cmd
+}
+
+func recurAddCmd(db *sql.DB) *cobra.Command {
+	var r recur.Rule
+	var at, every, on, from, until string
+	var except []string
+
+	cmd := &cobra.Command{
+		Use:   "add [name]",
+		Short: "Add a recurrence rule",
+		Example: `  go-time recur add standup --at 09:30-09:45 --every daily --on mon,tue,wed,thu,fri -t meeting
+  go-time recur add retro --at 14:00-15:00 --every weekly --interval 2 --on thu`,
+		Args: cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			r.Name = args[0]
+
+			var err error
+			if r.Frequency, err = recur.ParseFrequency(every); err != nil {
+				fmt.Println("Error:", err)
+				return
+			}
+			start, end, ok := strings.Cut(at, "-")
+			if !ok {
+				fmt.Printf("Error: invalid --at %q, expected HH:MM-HH:MM\n", at)
+				return
+			}
+			if r.Start, err = recur.ParseClock(start); err != nil {
+				fmt.Println("Error parsing --at:", err)
+				return
+			}
+			if r.End, err = recur.ParseClock(end); err != nil {
+				fmt.Println("Error parsing --at:", err)
+				return
+			}
+			if r.Weekdays, err = recur.ParseWeekdays(on); err != nil {
+				fmt.Println("Error parsing --on:", err)
+				return
+			}
+			if len(r.Weekdays) > 0 && r.Frequency == recur.Daily {
+				if r.Interval != 1 {
+					fmt.Println("Error: --on with --interval needs --every weekly")
+					return
+				}
+				// Daily on some weekdays is every week on those days.
+				r.Frequency = recur.Weekly
+			}
+
+			r.First = util.StartOfDay(time.Now())
+			if from != "" {
+				if r.First, err = recur.ParseDay(from); err != nil {
+					fmt.Println("Error parsing --from:", err)
+					return
+				}
+			}
+			if until != "" {
+				if r.Last, err = recur.ParseDay(until); err != nil {
+					fmt.Println("Error parsing --until:", err)
+					return
+				}
+			}
+			for _, s := range except {
+				day, err := recur.ParseDay(s)
+				if err != nil {
+					fmt.Println("Error parsing --except:", err)
+					return
+				}
+				r.Except = append(r.Except, day)
+			}
+
+			if err := recur.CreateRule(context.Background(), db, r); err != nil {
+				fmt.Println("Error adding rule:", err)
+				return
+			}
+			fmt.Printf("Rule added: %s %s. Create its entries with 'recur apply'.\n", r.Name, r.Schedule())
+		},
+	}
+
+	cmd.Flags().StringVar(&at, "at", "", "Time of day of each occurrence (HH:MM-HH:MM)")
+	cmd.Flags().StringVar(&every, "every", "daily", "How often the entry recurs (daily or weekly)")
+	cmd.Flags().IntVar(&r.Interval, "interval", 1, "Recur every this many days or weeks")
+	cmd.Flags().StringVar(&on, "on", "", "Weekdays to recur on, e.g. mon,wed,fri (default the weekday of --from)")
+	cmd.Flags().StringVar(&from, "from", "", "First day of the rule (YYYY-MM-DD, default today)")
+	cmd.Flags().StringVar(&until, "until", "", "Last day of the rule (YYYY-MM-DD, default none)")
+	cmd.Flags().StringSliceVar(&except, "except", nil, "Days to skip (YYYY-MM-DD)")
+	cmd.Flags().StringArrayVarP(&r.Tags, "tags", "t", nil, "Tags for the entries")
+	cmd.Flags().StringVarP(&r.Description, "description", "d", "", "Description of the entries")
+	cmd.MarkFlagRequired("at")
+
+	return cmd
+}
+
+func recurListCmd(db *sql.DB) *cobra.Command {
+	return &cobra.Command{
+		Use:   "list",
+		Short: "List all recurrence rules",
+		Args:  cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			rules, err := recur.GetRules(context.Background(), db)
+			if err != nil {
+				fmt.Println("Error listing rules:", err)
+				return
+			}
+			if len(rules) == 0 {
+				fmt.Println("No recurrence rules. Add one with 'recur add'.")
+				return
+			}
+			for _, r := range rules {
+				period := "from " + r.First.Format(recur.DayLayout)
+				if !r.Last.IsZero() {
+					period += " until " + r.Last.Format(recur.DayLayout)
+				}
+				fmt.Printf("%-20s %-40s %s\n", r.Name, r.Schedule(), period)
+				if len(r.Tags) > 0 {
+					fmt.Printf("  tags: %s\n", strings.Join(r.Tags, ", "))
+				}
+				if len(r.Except) > 0 {
+					days := make([]string, len(r.Except))
+					for i, day := range r.Except {
+						days[i] = day.Format(recur.DayLayout)
+					}
+					fmt.Printf("  except: %s\n", strings.Join(days, ", "))
+				}
+			}
+		},
+	}
+}
+
+func recurRmCmd(db *sql.DB) *cobra.Command {
+	return &cobra.Command{
+		Use:   "rm [name]",
+		Short: "Remove a recurrence rule, keeping its entries",
+		Args:  cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			if err := recur.DeleteRule(context.Background(), db, args[0]); err != nil {
+				fmt.Println("Error removing rule:", err)
+				return
+			}
+			fmt.Println("Rule removed:", args[0])
+		},
+	}
+}
+
+func recurSkipCmd(db *sql.DB) *cobra.Command {
+	return &cobra.Command{
+		Use:   "skip [name] [day]",
+		Short: "Skip a recurrence rule on a day (YYYY-MM-DD)",
+		Args:  cobra.ExactArgs(2),
+		Run: func(cmd *cobra.Command, args []string) {
+			day, err := recur.ParseDay(args[1])
+			if err != nil {
+				fmt.Println("Error:", err)
+				return
+			}
+			if err := recur.AddException(context.Background(), db, args[0], day); err != nil {
+				fmt.Println("Error skipping day:", err)
+				return
+			}
+			fmt.Printf("%s skipped on %s.\n", args[0], args[1])
+		},
+	}
+}
+
+func recurApplyCmd(db *sql.DB) *cobra.Command {
+	var until string
+
+	cmd := &cobra.Command{
+		Use:   "apply",
+		Short: "Create the entries of past occurrences",
+		Long: `Create an entry for every occurrence of the recurrence rules up to the day given by --until that has
+ended. Occurrences are only created once, and entries with the name of a rule that were tracked by hand count
+as its occurrences.`,
+		Args: cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			now := time.Now()
+			day := util.StartOfDay(now)
+			if until != "today" {
+				var err error
+				if day, err = recur.ParseDay(until); err != nil {
+					fmt.Println("Error parsing --until:", err)
+					return
+				}
+			}
+
+			applied, err := recur.Apply(context.Background(), db, day, now)
+			if err != nil {
+				fmt.Println("Error applying rules:", err)
+				return
+			}
+
+			created := 0
+			for _, a := range applied {
+				when := a.Day.Format(recur.DayLayout) + " " + a.Start.Format("15:04") + "-" + a.End.Format("15:04")
+				switch {
+				case a.Err != nil:
+					fmt.Printf("Skipped %s %s: %v\n", a.Rule, when, a.Err)
+				case a.Existing:
+					fmt.Printf("Already tracked %s %s as entry %d\n", a.Rule, when, a.EntryID)
+				default:
+					fmt.Printf("Created entry %d for %s %s\n", a.EntryID, a.Rule, when)
+					created++
+				}
+			}
+			fmt.Printf("Created %d time entries.\n", created)
+		},
+	}
+
+	cmd.Flags().StringVar(&until, "until", "today", "Last day to create entries for (today or YYYY-MM-DD)")
+
+	return cmd
+}
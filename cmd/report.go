@@ -0,0 +1,68 @@
+package cmd
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"github.com/spf13/cobra"
+	"go-time/pkgs/entry"
+	"go-time/pkgs/report"
+	"go-time/pkgs/util"
+	"time"
+)
+
+func ReportCmd(db *sql.DB) *cobra.Command {
+	var from, to string
+
+	cmd := &cobra.Command{
+		Use:   "report",
+		Short: "Show tracked time per day",
+		Long:  `Show the total tracked time for each day in a date range. Days start at midnight in the configured time zone.`,
+		Run: func(cmd *cobra.Command, args []string) {
+			ctx := context.Background()
+
+			now := time.Now()
+			toDay := util.StartOfDay(now)
+			fromDay := toDay.AddDate(0, 0, -6)
+
+			var err error
+			if from != "" {
+				if fromDay, err = parseDay(from); err != nil {
+					fmt.Println("Error parsing --from:", err)
+					return
+				}
+			}
+			if to != "" {
+				if toDay, err = parseDay(to); err != nil {
+					fmt.Println("Error parsing --to:", err)
+					return
+				}
+			}
+			if toDay.Before(fromDay) {
+				fmt.Println("Error: --to cannot be before --from")
+				return
+			}
+
+			entries, err := entry.ReadEntriesBetween(ctx, db, fromDay, toDay.AddDate(0, 0, 1))
+			if err != nil {
+				fmt.Println("Error reading time entries:", err)
+				return
+			}
+
+			totals := report.DailyTotals(entries, fromDay, toDay)
+			for _, total := range totals {
+				fmt.Printf("%s  %-9s %8s\n", total.Day.Format("2006-01-02"), total.Day.Format("Monday"), util.FormatDuration(total.Duration))
+			}
+			fmt.Printf("%-22s %8s\n", "Total", util.FormatDuration(report.Total(totals)))
+		},
+	}
+
+	cmd.Flags().StringVar(&from, "from", "", "First day of the report (YYYY-MM-DD, default 6 days ago)")
+	cmd.Flags().StringVar(&to, "to", "", "Last day of the report (YYYY-MM-DD, default today)")
+
+	return cmd
+}
+
+func parseDay(s string) (time.Time, error) {
+	return time.ParseInLocation("2006-01-02", s, util.Location())
+}
@@ -0,0 +1,45 @@
+package cmd
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"github.com/spf13/cobra"
+	"go-time/db"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+func RestoreCmd(database *sql.DB, backupDir string) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "restore [file]",
+		Short: "Restore the database from a backup",
+		Long:  `Restore the database from a backup file. The backup's schema is validated first and the current database is backed up before it is replaced.`,
+		Args:  cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			ctx := context.Background()
+			src := args[0]
+
+			if err := os.MkdirAll(backupDir, os.ModePerm); err != nil {
+				fmt.Println("Error creating backup directory:", err)
+				return
+			}
+
+			safety := filepath.Join(backupDir, "pre-restore-"+db.BackupFileName(time.Now()))
+			if err := db.Backup(ctx, database, safety); err != nil {
+				fmt.Println("Error backing up current database:", err)
+				return
+			}
+
+			if err := db.Restore(ctx, database, src); err != nil {
+				fmt.Println("Error restoring database:", err)
+				return
+			}
+			fmt.Println("Database restored from:", src)
+			fmt.Println("Previous database saved to:", safety)
+		},
+	}
+
+	return cmd
+}
@@ -0,0 +1,63 @@
+package cmd
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"github.com/spf13/cobra"
+	"go-time/pkgs/entry"
+	"go-time/pkgs/util"
+	"time"
+)
+
+func SplitCmd(db *sql.DB) *cobra.Command {
+	var id int
+	var at string
+
+	cmd := &cobra.Command{
+		Use:   "split",
+		Short: "Split a time entry in two",
+		Long: `Split a time entry at a point in time. The entry ends there and the rest of it becomes a new entry
+with the same name, description and tags. Without --at, you are asked where to split it.`,
+		Run: func(cmd *cobra.Command, args []string) {
+			ctx := context.Background()
+
+			var splitAt time.Time
+			var err error
+			if at != "" {
+				if splitAt, err = util.ParseTime(at); err != nil {
+					fmt.Println("Error parsing --at:", err)
+					return
+				}
+			} else {
+				e, err := entry.GetEntry(ctx, db, id)
+				if err != nil {
+					fmt.Println("Error reading time entry:", err)
+					return
+				}
+				form := entry.SplitForm(e)
+				if err := form.Run(); err != nil {
+					fmt.Println("Error running split form:", err)
+					return
+				}
+				if splitAt, err = entry.ReadSplitForm(form, e); err != nil {
+					fmt.Println("Error:", err)
+					return
+				}
+			}
+
+			newID, err := entry.SplitEntry(ctx, db, id, splitAt)
+			if err != nil {
+				fmt.Println("Error splitting time entry:", err)
+				return
+			}
+			fmt.Printf("Time entry %d split at %s, the rest is entry %d.\n", id, util.FormatTime(splitAt), newID)
+		},
+	}
+
+	cmd.Flags().IntVarP(&id, "id", "i", 0, "ID of the time entry to split")
+	cmd.Flags().StringVar(&at, "at", "", "Time to split the entry at (YYYY-MM-DD HH:MM:SS)")
+	cmd.MarkFlagRequired("id")
+
+	return cmd
+}
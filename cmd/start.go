@@ -13,17 +13,36 @@ func StartCmd(db *sql.DB) *cobra.Command {
 	var tags []string
 
 	cmd := &cobra.Command{
-		Use:   "start",
+		Use:   "start [@template]",
 		Short: "Start a new timer with optional tags",
-		Long:  `Start a new timer for a task with optional tags. Specify the task name and tags using flags.`,
+		Long: `Start a new timer for a task with optional tags. Specify the task name and tags using flags,
+or start from a template with 'start @name', adding any tags given with --tags.`,
+		Args: cobra.MaximumNArgs(1),
 		Run: func(cmd *cobra.Command, args []string) {
 			ctx := context.Background()
 
+			if len(args) > 0 {
+				taskName = args[0]
+			}
 			if taskName == "" {
 				log.Println("Task name is required. Use the --name flag to specify the task name.")
 				return
 			}
 
+			if name, ok := timer.TemplateAlias(taskName); ok {
+				t, err := timer.GetTemplate(ctx, db, name)
+				if err != nil {
+					log.Printf("Error starting timer: %v", err)
+					return
+				}
+				if err := t.Start(ctx, db, tags); err != nil {
+					log.Printf("Error starting timer: %v", err)
+				} else {
+					log.Println("Timer started for task:", t.Name)
+				}
+				return
+			}
+
 			if err := timer.CreateTimer(ctx, db, taskName, tags); err != nil {
 				log.Printf("Error starting timer: %v", err)
 			} else {
@@ -32,8 +51,7 @@ func StartCmd(db *sql.DB) *cobra.Command {
 		},
 	}
 
-	cmd.Flags().StringVarP(&taskName, "name", "n", "", "Name of the task")
-	cmd.MarkFlagRequired("name")
+	cmd.Flags().StringVarP(&taskName, "name", "n", "", "Name of the task, or @name of a template")
 	cmd.Flags().StringArrayVarP(&tags, "tags", "t", nil, "Tags for the timer")
 
 	return cmd
@@ -0,0 +1,72 @@
+package cmd
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"github.com/spf13/cobra"
+	"go-time/pkgs/config"
+	"go-time/pkgs/tag"
+	"go-time/pkgs/timer"
+	"go-time/pkgs/util"
+	"strings"
+	"time"
+)
+
+func StatusCmd(db *sql.DB, settings config.AppConfig) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "status",
+		Short: "Show the running timers and record a heartbeat",
+		Long: `Show the running timers with the time tracked so far, one per line, e.g. for a shell prompt or status bar.
+Every call records a heartbeat. A gap of at least idle_threshold minutes between heartbeats counts as idle
+time, which you are asked about when the timer is stopped.`,
+		Args: cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			ctx := context.Background()
+			now := time.Now()
+			threshold := time.Duration(settings.IdleThreshold) * time.Minute
+
+			if _, err := timer.Heartbeat(ctx, db, now, threshold); err != nil {
+				fmt.Println("Error recording heartbeat:", err)
+				return
+			}
+
+			timers, err := timer.ReadTimers(ctx, db)
+			if err != nil {
+				fmt.Println("Error listing timers:", err)
+				return
+			}
+			if len(timers) == 0 {
+				fmt.Println("No running timers")
+				return
+			}
+
+			timerTags, err := tag.GetTagsByTimer(ctx, db)
+			if err != nil {
+				fmt.Println("Error listing timers:", err)
+				return
+			}
+			for _, t := range timers {
+				line := fmt.Sprintf("%s %s", t.Name, util.FormatDuration(now.Sub(t.StartTime)))
+				if tags := tag.Names(timerTags[t.ID]); len(tags) > 0 {
+					line += " [" + strings.Join(tags, ", ") + "]"
+				}
+				idle, err := timer.IdlePeriods(ctx, db, t.Name, now, threshold)
+				if err != nil {
+					fmt.Println("Error listing timers:", err)
+					return
+				}
+				var idleTotal time.Duration
+				for _, i := range idle {
+					idleTotal += i.Duration()
+				}
+				if idleTotal > 0 {
+					line += fmt.Sprintf(" (idle %s)", util.FormatDuration(idleTotal))
+				}
+				fmt.Println(line)
+			}
+		},
+	}
+
+	return cmd
+}
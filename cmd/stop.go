@@ -4,17 +4,32 @@ import (
 	"context"
 	"database/sql"
 	"github.com/spf13/cobra"
+	"go-time/pkgs/config"
 	"go-time/pkgs/timer"
+	"go-time/pkgs/util"
 	"log"
+	"strconv"
+	"strings"
+	"time"
 )
 
-func StopCmd(db *sql.DB) *cobra.Command {
+func StopCmd(db *sql.DB, settings config.AppConfig) *cobra.Command {
 	var taskName string
+	var split bool
+	var idle string
+	var end string
 
 	cmd := &cobra.Command{
 		Use:   "stop",
 		Short: "Stop the current timer for a task",
-		Long:  `Stop the current timer for a task. Specify the task name using the --name flag.`,
+		Long: `Stop the current timer for a task. Specify the task name using the --name flag. Laps marked on the
+timer are listed in the description of the entry, or recorded as separate entries with --split.
+
+If no heartbeat was seen for idle_threshold minutes while the timer ran, you are asked whether to discard,
+keep or split off the idle time, unless --idle gives the answer.
+
+A timer that ran for longer than max_timer_hours was probably forgotten, so you are asked for the time you
+really stopped working on it. --end sets the end time without asking.`,
 		Run: func(cmd *cobra.Command, args []string) {
 			ctx := context.Background()
 
@@ -23,16 +38,89 @@ func StopCmd(db *sql.DB) *cobra.Command {
 				return
 			}
 
-			if err := timer.StopTimer(ctx, db, taskName); err != nil {
-				log.Printf("Error stopping timer: %v", err)
+			opts := timer.StopOptions{
+				Split:         split,
+				IdleThreshold: time.Duration(settings.IdleThreshold) * time.Minute,
+			}
+			if end != "" {
+				t, err := util.ParseTime(end)
+				if err != nil {
+					log.Println(err)
+					return
+				}
+				opts.End = t
+			} else {
+				t, err := timer.GetRunningTimer(ctx, db, taskName)
+				if err != nil {
+					log.Printf("Error stopping timer: %v", err)
+					return
+				}
+				limit := time.Duration(settings.MaxTimerHours) * time.Hour
+				if t.Overdue(time.Now(), limit) {
+					form := timer.RecoveryForm(t, limit)
+					if err := form.Run(); err != nil {
+						log.Printf("Error running recovery form: %v", err)
+						return
+					}
+					if opts.End, err = timer.ReadRecoveryForm(form, t); err != nil {
+						log.Println(err)
+						return
+					}
+				}
+			}
+
+			stopAt := opts.End
+			if stopAt.IsZero() {
+				stopAt = time.Now()
+			}
+			if idle != "" {
+				action, err := timer.ParseIdleAction(idle)
+				if err != nil {
+					log.Println(err)
+					return
+				}
+				opts.Idle = action
 			} else {
-				log.Println("Timer stopped for task:", taskName)
+				periods, err := timer.IdlePeriods(ctx, db, taskName, stopAt, opts.IdleThreshold)
+				if err != nil {
+					log.Printf("Error stopping timer: %v", err)
+					return
+				}
+				if len(periods) > 0 {
+					if opts.Idle, err = timer.PromptIdle(periods); err != nil {
+						log.Println(err)
+						return
+					}
+				}
+			}
+
+			entryIDs, err := timer.Stop(ctx, db, taskName, opts)
+			switch {
+			case err != nil:
+				log.Printf("Error stopping timer: %v", err)
+			case len(entryIDs) == 1:
+				log.Printf("Timer stopped for task: %s (entry %d)", taskName, entryIDs[0])
+			case len(entryIDs) == 0:
+				log.Printf("Timer stopped for task: %s (no time left to record)", taskName)
+			default:
+				log.Printf("Timer stopped for task: %s (entries %s)", taskName, joinIDs(entryIDs))
 			}
 		},
 	}
 
 	cmd.Flags().StringVarP(&taskName, "name", "n", "", "Name of the task to stop")
 	cmd.MarkFlagRequired("name")
+	cmd.Flags().BoolVar(&split, "split", false, "Record an entry for each lap of the timer")
+	cmd.Flags().StringVar(&end, "end", "", "When the timer really stopped (YYYY-MM-DD HH:MM:SS), defaults to now")
+	cmd.Flags().StringVar(&idle, "idle", "", "What to do with idle time without asking: discard, keep or split")
 
 	return cmd
 }
+
+func joinIDs(ids []int) string {
+	s := make([]string, len(ids))
+	for i, id := range ids {
+		s[i] = strconv.Itoa(id)
+	}
+	return strings.Join(s, ", ")
+}
@@ -0,0 +1,88 @@
+package cmd
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"github.com/spf13/cobra"
+	"go-time/pkgs/timer"
+	"strings"
+)
+
+func TemplateCmd(db *sql.DB) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "template",
+		Short: "Manage templates for starting timers",
+		Long:  `Manage named templates with tags, a description and a project. Start a timer from a template with 'start @name', or pick one in the timer form.`,
+	}
+
+	cmd.AddCommand(
+		templateAddCmd(db),
+		templateListCmd(db),
+		templateRmCmd(db),
+	)
+
+	return cmd
+}
+
+func templateAddCmd(db *sql.DB) *cobra.Command {
+	var t timer.Template
+
+	cmd := &cobra.Command{
+		Use:   "add [name]",
+		Short: "Add a template",
+		Args:  cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			t.Name = args[0]
+			if err := timer.CreateTemplate(context.Background(), db, t); err != nil {
+				fmt.Println("Error adding template:", err)
+				return
+			}
+			fmt.Printf("Template added, start it with: go-time start @%s\n", t.Name)
+		},
+	}
+
+	cmd.Flags().StringArrayVarP(&t.Tags, "tags", "t", nil, "Tags for timers started from the template")
+	cmd.Flags().StringVarP(&t.Description, "description", "d", "", "Description of the entries recorded from the template")
+	cmd.Flags().StringVarP(&t.Project, "project", "p", "", "Project of the entries recorded from the template")
+
+	return cmd
+}
+
+func templateListCmd(db *sql.DB) *cobra.Command {
+	return &cobra.Command{
+		Use:   "list",
+		Short: "List all templates",
+		Args:  cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			templates, err := timer.GetTemplates(context.Background(), db)
+			if err != nil {
+				fmt.Println("Error listing templates:", err)
+				return
+			}
+			if len(templates) == 0 {
+				fmt.Println("No templates. Add one with 'template add'.")
+				return
+			}
+			for _, t := range templates {
+				fmt.Printf("@%-20s %-15s %-25s %s\n", t.Name, t.Project, strings.Join(t.Tags, ", "), t.Description)
+			}
+		},
+	}
+}
+
+func templateRmCmd(db *sql.DB) *cobra.Command {
+	return &cobra.Command{
+		Use:   "rm [name]",
+		Short: "Remove a template",
+		Args:  cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			name := strings.TrimPrefix(args[0], "@")
+			if err := timer.DeleteTemplate(context.Background(), db, name); err != nil {
+				fmt.Println("Error removing template:", err)
+				return
+			}
+			fmt.Println("Template removed:", name)
+		},
+	}
+}
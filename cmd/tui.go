@@ -3,24 +3,24 @@ package cmd
 import (
 	"database/sql"
 	"github.com/spf13/cobra"
+	"go-time/pkgs/config"
 	"go-time/pkgs/tui"
 	"log"
 )
 
-func TuiCmd(db *sql.DB) *cobra.Command {
+func TuiCmd(db *sql.DB, settings config.AppConfig) *cobra.Command {
 	return &cobra.Command{
 		Use:   "tui",
 		Short: "Launch the Text-based User Interface",
 		Long:  "Launch the Text-based User Interface (TUI) for interactive management of timers and entries.",
 		Run: func(cmd *cobra.Command, args []string) {
-			if err := startTUI(db); err != nil {
+			if err := startTUI(db, settings); err != nil {
 				log.Fatalf("Failed to start TUI: %v", err)
 			}
 		},
 	}
 }
 
-func startTUI(db *sql.DB) error {
-	tui.Main(db)
-	return nil
+func startTUI(db *sql.DB, settings config.AppConfig) error {
+	return tui.Main(db, settings)
 }
@@ -0,0 +1,226 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/mattn/go-sqlite3"
+)
+
+// requiredSchema lists the tables and columns a database must have before it
+// can be restored. Tables added later are created by InitDB on demand.
+var requiredSchema = map[string][]string{
+	"entries":    {"id", "name", "description", "start_time", "end_time"},
+	"timers":     {"id", "is_running", "name", "start_time"},
+	"tags":       {"id", "name"},
+	"entry_tags": {"entry_id", "tag_id"},
+	"timer_tags": {"timer_id", "tag_id"},
+}
+
+const dailyBackupPattern = "go-time-????-??-??.db"
+
+// Backup copies the live database into dest using SQLite's online backup API.
+func Backup(ctx context.Context, db *sql.DB, dest string) error {
+	uri, err := fileURI(dest, "")
+	if err != nil {
+		return fmt.Errorf("error opening backup file: %w", err)
+	}
+	destDB, err := sql.Open("sqlite3", uri)
+	if err != nil {
+		return fmt.Errorf("error opening backup file: %w", err)
+	}
+	defer destDB.Close()
+
+	if err := copyDatabase(ctx, destDB, db); err != nil {
+		return fmt.Errorf("error backing up database: %w", err)
+	}
+	return nil
+}
+
+// Restore validates the database at src and replaces the contents of the
+// live database with it.
+func Restore(ctx context.Context, db *sql.DB, src string) error {
+	if _, err := os.Stat(src); err != nil {
+		return fmt.Errorf("error opening backup file: %w", err)
+	}
+
+	uri, err := fileURI(src, "mode=ro")
+	if err != nil {
+		return fmt.Errorf("error opening backup file: %w", err)
+	}
+	srcDB, err := sql.Open("sqlite3", uri)
+	if err != nil {
+		return fmt.Errorf("error opening backup file: %w", err)
+	}
+	defer srcDB.Close()
+
+	if err := ValidateSchema(ctx, srcDB); err != nil {
+		return fmt.Errorf("invalid backup %s: %w", src, err)
+	}
+
+	if err := copyDatabase(ctx, db, srcDB); err != nil {
+		return fmt.Errorf("error restoring database: %w", err)
+	}
+
+	if err := createTables(db); err != nil {
+		return err
+	}
+	return migrate(db)
+}
+
+// fileURI returns an SQLite URI for the file at path with query parameters.
+// The path is escaped so that characters such as ?, # and % are taken
+// literally instead of starting the parameters.
+func fileURI(path, query string) (string, error) {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return "", err
+	}
+	// Windows paths such as C:/x need a leading slash to not be read as a
+	// host.
+	path = filepath.ToSlash(abs)
+	if !strings.HasPrefix(path, "/") {
+		path = "/" + path
+	}
+	u := url.URL{Scheme: "file", Path: path, RawQuery: query}
+	return u.String(), nil
+}
+
+// ValidateSchema checks the integrity of the database and that it contains
+// every table and column go-time depends on.
+func ValidateSchema(ctx context.Context, db *sql.DB) error {
+	var result string
+	if err := db.QueryRowContext(ctx, "PRAGMA integrity_check").Scan(&result); err != nil {
+		return fmt.Errorf("error checking integrity: %w", err)
+	}
+	if result != "ok" {
+		return fmt.Errorf("integrity check failed: %s", result)
+	}
+
+	for table, columns := range requiredSchema {
+		existing, err := tableColumns(ctx, db, table)
+		if err != nil {
+			return err
+		}
+		if len(existing) == 0 {
+			return fmt.Errorf("missing table %q", table)
+		}
+		for _, column := range columns {
+			if !existing[column] {
+				return fmt.Errorf("table %q is missing column %q", table, column)
+			}
+		}
+	}
+	return nil
+}
+
+// AutoBackup writes at most one backup per day into dir and removes the
+// oldest daily backups so that only retention of them are kept. A retention
+// of zero disables automatic backups.
+func AutoBackup(ctx context.Context, db *sql.DB, dir string, retention int) (string, error) {
+	if retention <= 0 {
+		return "", nil
+	}
+
+	if err := os.MkdirAll(dir, os.ModePerm); err != nil {
+		return "", fmt.Errorf("error creating backup directory: %w", err)
+	}
+
+	dest := filepath.Join(dir, "go-time-"+time.Now().Format("2006-01-02")+".db")
+	if _, err := os.Stat(dest); err == nil {
+		return "", nil
+	}
+
+	if err := Backup(ctx, db, dest); err != nil {
+		return "", err
+	}
+
+	if err := rotateBackups(dir, retention); err != nil {
+		return dest, err
+	}
+	return dest, nil
+}
+
+// BackupFileName returns a timestamped file name for an on-demand backup.
+func BackupFileName(t time.Time) string {
+	return "go-time-" + t.Format("20060102-150405") + ".db"
+}
+
+func rotateBackups(dir string, retention int) error {
+	files, err := filepath.Glob(filepath.Join(dir, dailyBackupPattern))
+	if err != nil {
+		return fmt.Errorf("error listing backups: %w", err)
+	}
+
+	// Daily backup names sort chronologically, newest last.
+	sort.Strings(files)
+	for len(files) > retention {
+		if err := os.Remove(files[0]); err != nil {
+			return fmt.Errorf("error removing old backup: %w", err)
+		}
+		files = files[1:]
+	}
+	return nil
+}
+
+func copyDatabase(ctx context.Context, dest, src *sql.DB) error {
+	destConn, err := dest.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer destConn.Close()
+
+	srcConn, err := src.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer srcConn.Close()
+
+	return destConn.Raw(func(destDriverConn any) error {
+		return srcConn.Raw(func(srcDriverConn any) error {
+			destSQLite, ok := destDriverConn.(*sqlite3.SQLiteConn)
+			if !ok {
+				return fmt.Errorf("unexpected driver connection %T", destDriverConn)
+			}
+			srcSQLite, ok := srcDriverConn.(*sqlite3.SQLiteConn)
+			if !ok {
+				return fmt.Errorf("unexpected driver connection %T", srcDriverConn)
+			}
+
+			backup, err := destSQLite.Backup("main", srcSQLite, "main")
+			if err != nil {
+				return err
+			}
+			if _, err := backup.Step(-1); err != nil {
+				backup.Close()
+				return err
+			}
+			return backup.Finish()
+		})
+	})
+}
+
+func tableColumns(ctx context.Context, db *sql.DB, table string) (map[string]bool, error) {
+	rows, err := db.QueryContext(ctx, "SELECT name FROM pragma_table_info(?)", table)
+	if err != nil {
+		return nil, fmt.Errorf("error reading columns of %s: %w", table, err)
+	}
+	defer rows.Close()
+
+	columns := make(map[string]bool)
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, fmt.Errorf("error scanning column of %s: %w", table, err)
+		}
+		columns[name] = true
+	}
+	return columns, rows.Err()
+}
@@ -22,6 +22,11 @@ func InitDB(dbFile string) (*sql.DB, error) {
 		return nil, err
 	}
 
+	if err := migrate(db); err != nil {
+		db.Close()
+		return nil, err
+	}
+
 	return db, nil
 }
 
@@ -32,6 +37,15 @@ func createTables(db *sql.DB) error {
 		createTagsTable,
 		createEntryTagsTable,
 		createTimerTagsTable,
+		createEntryHistoryTable,
+		createTimerLapsTable,
+		createTimerIdleTable,
+		createTemplatesTable,
+		createTemplateTagsTable,
+		createRecurrencesTable,
+		createRecurrenceTagsTable,
+		createRecurrenceExceptionsTable,
+		createRecurrenceEntriesTable,
 	}
 
 	for _, createFunc := range tableCreators {
@@ -49,7 +63,10 @@ func createEntriesTable(db *sql.DB) error {
         name TEXT NOT NULL,
         description TEXT,
         start_time DATETIME NOT NULL,
-        end_time DATETIME NOT NULL
+        end_time DATETIME NOT NULL,
+        start_offset INTEGER NOT NULL DEFAULT 0,
+        end_offset INTEGER NOT NULL DEFAULT 0,
+        project TEXT
     );`
 	_, err := db.Exec(sql)
 	return err
@@ -61,7 +78,11 @@ func createTimersTable(db *sql.DB) error {
         id INTEGER PRIMARY KEY AUTOINCREMENT,
         is_running BOOLEAN NOT NULL,
         name TEXT,
-        start_time DATETIME
+        start_time DATETIME,
+        start_offset INTEGER NOT NULL DEFAULT 0,
+        last_seen DATETIME,
+        description TEXT,
+        project TEXT
     );`
 	_, err := db.Exec(sql)
 	return err
@@ -102,3 +123,134 @@ func createTimerTagsTable(db *sql.DB) error {
 	_, err := db.Exec(sql)
 	return err
 }
+
+func createEntryHistoryTable(db *sql.DB) error {
+	sql := `
+    CREATE TABLE IF NOT EXISTS entry_history (
+        id INTEGER PRIMARY KEY AUTOINCREMENT,
+        entry_id INTEGER NOT NULL,
+        action TEXT NOT NULL,
+        changed_at DATETIME NOT NULL,
+        name TEXT NOT NULL,
+        description TEXT,
+        start_time DATETIME NOT NULL,
+        end_time DATETIME NOT NULL,
+        tags TEXT NOT NULL DEFAULT '',
+        project TEXT
+    );`
+	_, err := db.Exec(sql)
+	return err
+}
+
+func createTimerLapsTable(db *sql.DB) error {
+	sql := `
+    CREATE TABLE IF NOT EXISTS timer_laps (
+        id INTEGER PRIMARY KEY AUTOINCREMENT,
+        timer_id INTEGER NOT NULL,
+        lap_time DATETIME NOT NULL,
+        lap_offset INTEGER NOT NULL DEFAULT 0,
+        note TEXT,
+        FOREIGN KEY (timer_id) REFERENCES timers(id) ON DELETE CASCADE
+    );`
+	_, err := db.Exec(sql)
+	return err
+}
+
+func createTimerIdleTable(db *sql.DB) error {
+	sql := `
+    CREATE TABLE IF NOT EXISTS timer_idle (
+        id INTEGER PRIMARY KEY AUTOINCREMENT,
+        timer_id INTEGER NOT NULL,
+        start_time DATETIME NOT NULL,
+        end_time DATETIME NOT NULL,
+        FOREIGN KEY (timer_id) REFERENCES timers(id) ON DELETE CASCADE
+    );`
+	_, err := db.Exec(sql)
+	return err
+}
+
+func createTemplatesTable(db *sql.DB) error {
+	sql := `
+    CREATE TABLE IF NOT EXISTS templates (
+        id INTEGER PRIMARY KEY AUTOINCREMENT,
+        name TEXT NOT NULL UNIQUE,
+        description TEXT,
+        project TEXT
+    );`
+	_, err := db.Exec(sql)
+	return err
+}
+
+func createTemplateTagsTable(db *sql.DB) error {
+	sql := `
+    CREATE TABLE IF NOT EXISTS template_tags (
+        template_id INTEGER NOT NULL,
+        tag_id INTEGER NOT NULL,
+        PRIMARY KEY (template_id, tag_id),
+        FOREIGN KEY (template_id) REFERENCES templates(id) ON DELETE CASCADE,
+        FOREIGN KEY (tag_id) REFERENCES tags(id) ON DELETE CASCADE
+    );`
+	_, err := db.Exec(sql)
+	return err
+}
+
+// Recurrence rules keep their days as YYYY-MM-DD and their times of day as
+// HH:MM, since they are calendar days and clock times in whatever zone is
+// configured rather than instants.
+func createRecurrencesTable(db *sql.DB) error {
+	sql := `
+    CREATE TABLE IF NOT EXISTS recurrences (
+        id INTEGER PRIMARY KEY AUTOINCREMENT,
+        name TEXT NOT NULL UNIQUE,
+        description TEXT,
+        frequency TEXT NOT NULL,
+        interval INTEGER NOT NULL DEFAULT 1,
+        weekdays TEXT NOT NULL DEFAULT '',
+        start_clock TEXT NOT NULL,
+        end_clock TEXT NOT NULL,
+        first_day TEXT NOT NULL,
+        last_day TEXT
+    );`
+	_, err := db.Exec(sql)
+	return err
+}
+
+func createRecurrenceTagsTable(db *sql.DB) error {
+	sql := `
+    CREATE TABLE IF NOT EXISTS recurrence_tags (
+        recurrence_id INTEGER NOT NULL,
+        tag_id INTEGER NOT NULL,
+        PRIMARY KEY (recurrence_id, tag_id),
+        FOREIGN KEY (recurrence_id) REFERENCES recurrences(id) ON DELETE CASCADE,
+        FOREIGN KEY (tag_id) REFERENCES tags(id) ON DELETE CASCADE
+    );`
+	_, err := db.Exec(sql)
+	return err
+}
+
+func createRecurrenceExceptionsTable(db *sql.DB) error {
+	sql := `
+    CREATE TABLE IF NOT EXISTS recurrence_exceptions (
+        recurrence_id INTEGER NOT NULL,
+        day TEXT NOT NULL,
+        PRIMARY KEY (recurrence_id, day),
+        FOREIGN KEY (recurrence_id) REFERENCES recurrences(id) ON DELETE CASCADE
+    );`
+	_, err := db.Exec(sql)
+	return err
+}
+
+// recurrence_entries records the occurrences already turned into entries,
+// so that applying the rules again does not duplicate them.
+func createRecurrenceEntriesTable(db *sql.DB) error {
+	sql := `
+    CREATE TABLE IF NOT EXISTS recurrence_entries (
+        recurrence_id INTEGER NOT NULL,
+        day TEXT NOT NULL,
+        entry_id INTEGER NOT NULL,
+        PRIMARY KEY (recurrence_id, day),
+        FOREIGN KEY (recurrence_id) REFERENCES recurrences(id) ON DELETE CASCADE
+    );`
+	_, err := db.Exec(sql)
+	return err
+}
@@ -0,0 +1,195 @@
+package db
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// migrations upgrade databases created by earlier versions. Once migration i
+// has run, PRAGMA user_version is set to i+1. Migrations must tolerate tables
+// that createTables has already created with the latest schema.
+var migrations = []func(*sql.Tx) error{
+	migrateUTCTimes,
+	migrateTimerHeartbeat,
+	migrateTimerDescription,
+	migrateEntryProject,
+	migrateTemplateProject,
+	migrateHistoryTags,
+}
+
+func migrate(db *sql.DB) error {
+	var version int
+	if err := db.QueryRow("PRAGMA user_version").Scan(&version); err != nil {
+		return fmt.Errorf("error reading schema version: %w", err)
+	}
+
+	for i := version; i < len(migrations); i++ {
+		tx, err := db.Begin()
+		if err != nil {
+			return fmt.Errorf("error starting migration: %w", err)
+		}
+		if err := migrations[i](tx); err != nil {
+			tx.Rollback()
+			return fmt.Errorf("error migrating database to version %d: %w", i+1, err)
+		}
+		if _, err := tx.Exec(fmt.Sprintf("PRAGMA user_version = %d", i+1)); err != nil {
+			tx.Rollback()
+			return fmt.Errorf("error updating schema version: %w", err)
+		}
+		if err := tx.Commit(); err != nil {
+			return fmt.Errorf("error committing migration: %w", err)
+		}
+	}
+	return nil
+}
+
+// migrateUTCTimes adds zone offset columns and rewrites every stored time in
+// UTC, keeping the zone it was recorded in as an offset.
+func migrateUTCTimes(tx *sql.Tx) error {
+	columns := []struct{ table, column string }{
+		{"entries", "start_offset"},
+		{"entries", "end_offset"},
+		{"timers", "start_offset"},
+	}
+	for _, c := range columns {
+		if err := addColumn(tx, c.table, c.column, "INTEGER NOT NULL DEFAULT 0"); err != nil {
+			return err
+		}
+	}
+
+	type row struct {
+		id         int
+		start, end time.Time
+	}
+
+	var entries []row
+	rows, err := tx.Query("SELECT id, start_time, end_time FROM entries")
+	if err != nil {
+		return err
+	}
+	for rows.Next() {
+		var r row
+		if err := rows.Scan(&r.id, &r.start, &r.end); err != nil {
+			rows.Close()
+			return err
+		}
+		entries = append(entries, r)
+	}
+	rows.Close()
+	for _, r := range entries {
+		_, startOffset := r.start.Zone()
+		_, endOffset := r.end.Zone()
+		if _, err := tx.Exec("UPDATE entries SET start_time = ?, end_time = ?, start_offset = ?, end_offset = ? WHERE id = ?",
+			r.start.UTC(), r.end.UTC(), startOffset, endOffset, r.id); err != nil {
+			return err
+		}
+	}
+
+	var timers []row
+	rows, err = tx.Query("SELECT id, start_time FROM timers WHERE start_time IS NOT NULL")
+	if err != nil {
+		return err
+	}
+	for rows.Next() {
+		var r row
+		if err := rows.Scan(&r.id, &r.start); err != nil {
+			rows.Close()
+			return err
+		}
+		timers = append(timers, r)
+	}
+	rows.Close()
+	for _, r := range timers {
+		_, offset := r.start.Zone()
+		if _, err := tx.Exec("UPDATE timers SET start_time = ?, start_offset = ? WHERE id = ?", r.start.UTC(), offset, r.id); err != nil {
+			return err
+		}
+	}
+
+	_, err = tx.Exec(`
+    UPDATE entry_history
+    SET changed_at = datetime(changed_at), start_time = datetime(start_time), end_time = datetime(end_time)`)
+	return err
+}
+
+// migrateTimerHeartbeat adds the time of the last heartbeat to timers, used
+// to detect idle time.
+func migrateTimerHeartbeat(tx *sql.Tx) error {
+	return addColumn(tx, "timers", "last_seen", "DATETIME")
+}
+
+// migrateTimerDescription adds a description to timers, which timers
+// started from a template pass on to their entries.
+func migrateTimerDescription(tx *sql.Tx) error {
+	return addColumn(tx, "timers", "description", "TEXT")
+}
+
+// migrateEntryProject adds the project an entry belongs to, to entries and
+// their history.
+func migrateEntryProject(tx *sql.Tx) error {
+	if err := addColumn(tx, "entries", "project", "TEXT"); err != nil {
+		return err
+	}
+	return addColumn(tx, "entry_history", "project", "TEXT")
+}
+
+// migrateTemplateProject adds a project to templates and to the timers
+// started from them, which pass it on to their entries.
+func migrateTemplateProject(tx *sql.Tx) error {
+	if err := addColumn(tx, "templates", "project", "TEXT"); err != nil {
+		return err
+	}
+	return addColumn(tx, "timers", "project", "TEXT")
+}
+
+// migrateHistoryTags rewrites the tags of history records, which were
+// stored separated by commas, as JSON arrays so that tags containing commas
+// survive.
+func migrateHistoryTags(tx *sql.Tx) error {
+	type row struct {
+		id   int
+		tags string
+	}
+
+	var records []row
+	rows, err := tx.Query("SELECT id, tags FROM entry_history WHERE tags != '' AND tags NOT LIKE '[%'")
+	if err != nil {
+		return err
+	}
+	for rows.Next() {
+		var r row
+		if err := rows.Scan(&r.id, &r.tags); err != nil {
+			rows.Close()
+			return err
+		}
+		records = append(records, r)
+	}
+	rows.Close()
+
+	for _, r := range records {
+		tags, err := json.Marshal(strings.Split(r.tags, ","))
+		if err != nil {
+			return err
+		}
+		if _, err := tx.Exec("UPDATE entry_history SET tags = ? WHERE id = ?", string(tags), r.id); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func addColumn(tx *sql.Tx, table, column, definition string) error {
+	var count int
+	err := tx.QueryRow("SELECT COUNT(*) FROM pragma_table_info(?) WHERE name = ?", table, column).Scan(&count)
+	if err != nil {
+		return fmt.Errorf("error reading columns of %s: %w", table, err)
+	}
+	if count > 0 {
+		return nil
+	}
+	_, err = tx.Exec(fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s %s", table, column, definition))
+	return err
+}
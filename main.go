@@ -1,29 +1,74 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"github.com/spf13/cobra"
 	"go-time/cmd"
 	"go-time/db"
 	"go-time/pkgs/config"
+	"go-time/pkgs/entry"
+	"go-time/pkgs/util"
 	"log"
 	"os"
 	"path/filepath"
+	"strings"
 )
 
+// globalFlags maps the global command line flags to the config keys they
+// override. They are taken out of the command line wherever they appear, so
+// no subcommand may define a flag with the same name.
+var globalFlags = map[string]string{
+	"profile": "profile",
+	"db-path": "db_path",
+	"tz":      "timezone",
+}
+
 func main() {
-	config := config.New("go-time", "config.toml")
-	configDir := filepath.Join(os.Getenv("HOME"), ".config", "go-time")
-	dbFile := config.Get("db_path", "go-time.db").(string)
-
-	if _, err := os.Stat(configDir); os.IsNotExist(err) {
-		err := os.MkdirAll(configDir, os.ModePerm)
-		if err != nil {
-			log.Fatal(err)
+	flags, args := splitGlobalFlags(os.Args[1:])
+
+	cfg, err := config.New("go-time", "config.toml")
+	if err != nil {
+		fmt.Println("Error loading config:", err)
+		// The config commands are needed to fix the config, so they still
+		// run, with the default settings.
+		if len(args) == 0 || args[0] != "config" {
+			os.Exit(1)
+		}
+		fmt.Println("Using the default settings.")
+	}
+	for _, warning := range cfg.Warnings {
+		fmt.Fprintln(os.Stderr, "Warning:", warning)
+	}
+
+	for flag, value := range flags {
+		if err := cfg.Override(globalFlags[flag], value); err != nil {
+			fmt.Println("Error applying flag:", err)
+			os.Exit(1)
 		}
 	}
 
-	dbFilePath := filepath.Join(configDir, dbFile)
+	profile := cfg.ActiveProfile()
+	settings, err := cfg.ProfileSettings(profile)
+	if err != nil {
+		fmt.Println("Error loading profile:", err)
+		os.Exit(1)
+	}
+
+	loc, err := util.LoadLocation(settings.Timezone)
+	if err != nil {
+		fmt.Println("Error loading time zone:", err)
+		os.Exit(1)
+	}
+	util.SetLocation(loc)
+
+	entry.SetStrict(settings.StrictEntries)
+
+	dbFilePath := cfg.ResolvePath(settings.DBPath)
+	if err := os.MkdirAll(filepath.Dir(dbFilePath), os.ModePerm); err != nil {
+		log.Fatal(err)
+	}
+
 	database, err := db.InitDB(dbFilePath)
 	if err != nil {
 		fmt.Println("Error initializing database:", err)
@@ -31,27 +76,51 @@ func main() {
 	}
 	defer database.Close()
 
+	backupDir := cfg.ResolvePath(settings.BackupDir)
+	if _, err := db.AutoBackup(context.Background(), database, backupDir, settings.BackupRetention); err != nil {
+		fmt.Println("Error creating automatic backup:", err)
+	}
+
 	var rootCmd = &cobra.Command{
 		Use:   "go-time",
 		Short: "Go-Time is a time tracking application",
 	}
+	rootCmd.PersistentFlags().String("profile", "", "Profile to use (overrides GO_TIME_PROFILE and the config file)")
+	rootCmd.PersistentFlags().String("tz", "", "Time zone used to display times (overrides GO_TIME_TIMEZONE and the config file)")
+	rootCmd.PersistentFlags().String("db-path", "", "Path to the SQLite database file (overrides GO_TIME_DB_PATH and the config file)")
 
 	rootCmd.AddCommand(
 		cmd.CreateCmd(database),
 		cmd.StartCmd(database),
-		cmd.StopCmd(database),
+		cmd.StopCmd(database, settings),
+		cmd.LapCmd(database),
+		cmd.StatusCmd(database, settings),
 		cmd.EditCmd(database),
-		cmd.ReadCmd(database),
-		cmd.TuiCmd(database),
+		cmd.ReadCmd(database, settings),
+		cmd.TuiCmd(database, settings),
+		cmd.PomodoroCmd(database, settings),
 		cmd.DelCmd(database),
+		cmd.SplitCmd(database),
+		cmd.MergeCmd(database),
+		cmd.BulkCmd(database),
+		cmd.TemplateCmd(database),
+		cmd.RecurCmd(database),
+		cmd.HistoryCmd(database),
+		cmd.ReportCmd(database),
+		cmd.CheckCmd(database),
+		cmd.BackupCmd(database, backupDir),
+		cmd.RestoreCmd(database, backupDir),
+		cmd.ProfileCmd(cfg, profile),
+		cmd.ConfigCmd(cfg),
 	)
 
 	// Check if no subcommand is provided and apply command mode setting
-	if len(os.Args) == 1 {
-		commandMode := config.Get("command_mode", "cli").(string)
-		switch commandMode {
+	if len(args) == 0 {
+		switch settings.CommandMode {
 		case "tui":
-			if err := cmd.TuiCmd(database).Execute(); err != nil {
+			tuiCmd := cmd.TuiCmd(database, settings)
+			tuiCmd.SetArgs([]string{})
+			if err := tuiCmd.Execute(); err != nil {
 				fmt.Println("Error executing TUI command:", err)
 				os.Exit(1)
 			}
@@ -76,5 +145,29 @@ func main() {
 		fmt.Println("Error executing command:", err)
 		os.Exit(1)
 	}
+}
 
+// splitGlobalFlags extracts the global flags from args so that the config
+// can be resolved and the database opened before the command line is
+// executed.
+func splitGlobalFlags(args []string) (map[string]string, []string) {
+	flags := make(map[string]string)
+	var rest []string
+	for i := 0; i < len(args); i++ {
+		name, value, hasValue := strings.Cut(strings.TrimPrefix(args[i], "--"), "=")
+		if _, ok := globalFlags[name]; !ok || !strings.HasPrefix(args[i], "--") {
+			rest = append(rest, args[i])
+			continue
+		}
+		if !hasValue {
+			if i+1 >= len(args) {
+				rest = append(rest, args[i])
+				continue
+			}
+			value = args[i+1]
+			i++
+		}
+		flags[name] = value
+	}
+	return flags, rest
 }
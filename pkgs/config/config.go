@@ -1,110 +1,539 @@
 package config
 
 import (
+	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
 
 	"github.com/BurntSushi/toml"
 )
 
+const (
+	SourceDefault = "default"
+	SourceFile    = "file"
+	SourceEnv     = "env"
+	SourceFlag    = "flag"
+)
+
+// Config holds the application settings. Values are layered with defaults
+// first, then the config file, then environment variables, then flags.
 type Config struct {
 	ConfigDir  string
 	ConfigFile string
+	DataDir    string
 	Settings   AppConfig
+
+	// Warnings lists problems with the config file that did not prevent it
+	// from loading, such as unknown keys.
+	Warnings []string
+
+	file    AppConfig
+	sources map[string]string
+	// explicit holds the keys set in the config file, which are the only
+	// ones Save writes so that the other keys keep following the defaults.
+	explicit map[string]bool
+	// invalid is the error that made Reload fall back to the defaults.
+	// Nothing is saved while it is set, so the config file is not
+	// overwritten with them.
+	invalid error
 }
 
 type AppConfig struct {
-	DBPath      string `toml:"db_path"`
-	CommandMode string `toml:"command_mode"`
+	DBPath          string `toml:"db_path"`
+	CommandMode     string `toml:"command_mode"`
+	BackupDir       string `toml:"backup_dir"`
+	BackupRetention int    `toml:"backup_retention"`
+	Timezone        string `toml:"timezone"`
+	Theme           string `toml:"theme"`
+	IdleThreshold   int    `toml:"idle_threshold"`
+	MaxTimerHours   int    `toml:"max_timer_hours"`
+	StrictEntries   bool   `toml:"strict_entries"`
+
+	PomodoroWork      int `toml:"pomodoro_work"`
+	PomodoroBreak     int `toml:"pomodoro_break"`
+	PomodoroLongBreak int `toml:"pomodoro_long_break"`
+	PomodoroCycles    int `toml:"pomodoro_cycles"`
+
+	// Keys rebinds TUI actions to lists of keys, and Colors overrides the
+	// colors of the TUI theme by name.
+	Keys   map[string][]string `toml:"keys,omitempty"`
+	Colors map[string]string   `toml:"colors,omitempty"`
+
+	Profile  string             `toml:"profile,omitempty"`
+	Profiles map[string]Profile `toml:"profiles,omitempty"`
 }
 
-func New(appname string, filename string) *Config {
-	configDir := filepath.Join(os.Getenv("HOME"), ".config", appname)
-	configFile := filepath.Join(configDir, filename)
+// Profile overrides the top-level settings for a named workspace, by the
+// same keys as the config file. Settings it leaves out fall back to the
+// top-level value, except that the database defaults to <name>.db and
+// backups go to a directory named after the profile.
+type Profile map[string]any
+
+// apply sets the values of the profile in a, validated like those of the
+// config file, leaving out the keys for which skip returns true.
+func (p Profile) apply(a *AppConfig, skip func(key string) bool) error {
+	keys := make([]string, 0, len(p))
+	for key := range p {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
 
-	if _, err := os.Stat(configDir); os.IsNotExist(err) {
-		os.MkdirAll(configDir, os.ModePerm)
+	for _, key := range keys {
+		if key == "profile" {
+			return fmt.Errorf("a profile cannot select another profile")
+		}
+		s, err := lookup(key)
+		if err != nil {
+			return err
+		}
+		if skip != nil && skip(key) {
+			continue
+		}
+		if err := s.set(a, fmt.Sprint(p[key])); err != nil {
+			return fmt.Errorf("invalid value for %s: %w", key, err)
+		}
 	}
+	return nil
+}
+
+// Set validates value for key and stores it in the profile with the type it
+// has in the config file.
+func (p Profile) Set(key, value string) error {
+	s, err := lookup(key)
+	if err != nil {
+		return err
+	}
+	if key == "profile" {
+		return fmt.Errorf("a profile cannot select another profile")
+	}
+	a := defaults()
+	if err := s.set(&a, value); err != nil {
+		return fmt.Errorf("invalid value for %s: %w", key, err)
+	}
+
+	// Round-trip through TOML so that numbers and booleans are not saved
+	// as strings.
+	var b strings.Builder
+	if err := toml.NewEncoder(&b).Encode(a); err != nil {
+		return fmt.Errorf("error encoding %s: %w", key, err)
+	}
+	values := make(map[string]any)
+	if _, err := toml.Decode(b.String(), &values); err != nil {
+		return fmt.Errorf("error encoding %s: %w", key, err)
+	}
+	p[key] = values[key]
+	return nil
+}
+
+// Value is a single resolved setting together with the layer it came from.
+type Value struct {
+	Key         string
+	Value       string
+	Source      string
+	Description string
+}
+
+// DefaultProfile is the name used for the top-level settings.
+const DefaultProfile = "default"
+
+var commandModes = []string{"cli", "tui", "help"}
+
+// Themes are the names of the built-in TUI color themes.
+var Themes = []string{"dark", "light", "high-contrast"}
+
+func defaults() AppConfig {
+	return AppConfig{
+		DBPath:          "go-time.db",
+		CommandMode:     "cli",
+		BackupDir:       "backups",
+		BackupRetention: 7,
+		Timezone:        "Local",
+		Theme:           "dark",
+		IdleThreshold:   10,
+		MaxTimerHours:   12,
+
+		PomodoroWork:      25,
+		PomodoroBreak:     5,
+		PomodoroLongBreak: 15,
+		PomodoroCycles:    4,
+	}
+}
+
+// New resolves the config and data directories, loads the config file if it
+// exists and applies environment overrides. It never writes to disk. If the
+// config file or the environment holds invalid values, the error is returned
+// together with a Config that uses the defaults, so that the config can
+// still be inspected and edited.
+func New(appname string, filename string) (*Config, error) {
+	configDir := filepath.Join(xdgDir("XDG_CONFIG_HOME", ".config"), appname)
 
 	c := &Config{
 		ConfigDir:  configDir,
-		ConfigFile: configFile,
-		Settings: AppConfig{
-			DBPath:      "go-time.db",
-			CommandMode: "cli",
-		},
+		ConfigFile: filepath.Join(configDir, filename),
+		DataDir:    filepath.Join(xdgDir("XDG_DATA_HOME", filepath.Join(".local", "share")), appname),
+	}
+	return c, c.Reload()
+}
+
+// Reload re-reads the config file and re-applies environment overrides,
+// discarding any flag overrides. If any value is invalid, every setting falls
+// back to its default and the error is returned.
+func (c *Config) Reload() error {
+	c.invalid = c.reload()
+	if c.invalid != nil {
+		c.file = defaults()
+		c.Settings = c.file
+		c.explicit = make(map[string]bool)
+		for _, s := range settings {
+			c.sources[s.key] = SourceDefault
+		}
+	}
+	return c.invalid
+}
+
+func (c *Config) reload() error {
+	c.file = defaults()
+	c.Warnings = nil
+	c.sources = make(map[string]string)
+	c.explicit = make(map[string]bool)
+	for _, s := range settings {
+		c.sources[s.key] = SourceDefault
+	}
+
+	if _, err := os.Stat(c.ConfigFile); err == nil {
+		if err := c.Load(); err != nil {
+			return err
+		}
+	} else if !os.IsNotExist(err) {
+		return fmt.Errorf("error reading config file: %w", err)
 	}
 
-	if _, err := os.Stat(configFile); err == nil {
-		c.Load()
-	} else {
-		c.SaveWithComments()
+	c.Settings = c.file
+	for _, s := range settings {
+		if value, ok := os.LookupEnv(s.env); ok && value != "" {
+			if err := s.set(&c.Settings, value); err != nil {
+				return fmt.Errorf("invalid value for %s: %w", s.env, err)
+			}
+			c.sources[s.key] = SourceEnv
+		}
 	}
 
-	return c
+	return c.validate()
 }
 
+// Load reads the config file into the file layer, reporting unknown keys as
+// warnings and invalid values as errors.
 func (c *Config) Load() error {
-	_, err := toml.DecodeFile(c.ConfigFile, &c.Settings)
-	return err
+	file := defaults()
+	md, err := toml.DecodeFile(c.ConfigFile, &file)
+	if err != nil {
+		return fmt.Errorf("error parsing %s: %w", c.ConfigFile, err)
+	}
+
+	for _, key := range md.Undecoded() {
+		c.Warnings = append(c.Warnings, fmt.Sprintf("unknown key %q in %s", key.String(), c.ConfigFile))
+	}
+	for _, s := range settings {
+		if md.IsDefined(s.key) {
+			if err := s.set(&file, s.get(&file)); err != nil {
+				return fmt.Errorf("invalid value for %s in %s: %w", s.key, c.ConfigFile, err)
+			}
+			c.sources[s.key] = SourceFile
+			c.explicit[s.key] = true
+		}
+	}
+
+	c.file = file
+	return nil
 }
 
+// Save writes the keys set in the config file, along with the key bindings,
+// colors and profiles, to the config file. Environment and flag overrides
+// are never persisted.
 func (c *Config) Save() error {
+	if c.invalid != nil {
+		return fmt.Errorf("not saving while the config is invalid, fix it first: %w", c.invalid)
+	}
+	if err := os.MkdirAll(c.ConfigDir, os.ModePerm); err != nil {
+		return fmt.Errorf("error creating config directory: %w", err)
+	}
+
+	// Round-trip the file layer through TOML to drop the keys that were never
+	// set while keeping the types of the others.
+	var b strings.Builder
+	if err := toml.NewEncoder(&b).Encode(c.file); err != nil {
+		return fmt.Errorf("error encoding config: %w", err)
+	}
+	values := make(map[string]any)
+	if _, err := toml.Decode(b.String(), &values); err != nil {
+		return fmt.Errorf("error encoding config: %w", err)
+	}
+	for _, s := range settings {
+		if !c.explicit[s.key] {
+			delete(values, s.key)
+		}
+	}
+
 	f, err := os.Create(c.ConfigFile)
 	if err != nil {
 		return err
 	}
 	defer f.Close()
 
-	return toml.NewEncoder(f).Encode(c.Settings)
+	return toml.NewEncoder(f).Encode(values)
 }
 
+// SaveWithComments writes a commented config file containing every setting.
 func (c *Config) SaveWithComments() error {
-	configWithComments := `# Path to the SQLite database file
-db_path = "` + c.Settings.DBPath + `"
+	if err := os.MkdirAll(c.ConfigDir, os.ModePerm); err != nil {
+		return fmt.Errorf("error creating config directory: %w", err)
+	}
+
+	var b strings.Builder
+	for _, s := range settings {
+		value := s.get(&c.file)
+		if s.omitEmpty && value == "" {
+			continue
+		}
+		if !s.numeric {
+			value = strconv.Quote(value)
+		}
+		fmt.Fprintf(&b, "# %s\n%s = %s\n\n", s.description, s.key, value)
+		c.explicit[s.key] = true
+	}
+	b.WriteString("# TUI key bindings by action, for example:\n# [keys]\n# add = [\"a\", \"enter\"]\n\n")
+	b.WriteString("# TUI colors overriding the theme, for example:\n# [colors]\n# cursor = \"#ff5f87\"\n\n")
 
-# Mode in which the application runs (cli, tui, help)
-command_mode = "` + c.Settings.CommandMode + `"
-`
+	return os.WriteFile(c.ConfigFile, []byte(strings.TrimSuffix(b.String(), "\n")), 0644)
+}
 
-	err := os.WriteFile(c.ConfigFile, []byte(configWithComments), 0644)
+// Override applies a flag value on top of every other layer.
+func (c *Config) Override(key, value string) error {
+	s, err := lookup(key)
 	if err != nil {
 		return err
 	}
+	if err := s.set(&c.Settings, value); err != nil {
+		return fmt.Errorf("invalid value for %s: %w", key, err)
+	}
+	c.sources[key] = SourceFlag
+	return c.validate()
+}
 
-	return nil
+// Set validates and stores a value in the config file.
+func (c *Config) Set(key, value string) error {
+	s, err := lookup(key)
+	if err != nil {
+		return err
+	}
+	if err := s.set(&c.file, value); err != nil {
+		return fmt.Errorf("invalid value for %s: %w", key, err)
+	}
+	if source := c.sources[key]; source == SourceDefault || source == SourceFile {
+		s.set(&c.Settings, value)
+		c.sources[key] = SourceFile
+	}
+	if err := c.validate(); err != nil {
+		return err
+	}
+	c.explicit[key] = true
+	return c.Save()
 }
 
-func (c *Config) Set(key string, value interface{}) {
-	switch key {
-	case "db_path":
-		c.Settings.DBPath = value.(string)
-	case "command_mode":
-		c.Settings.CommandMode = value.(string)
+// Get returns the effective value of key.
+func (c *Config) Get(key string) (string, error) {
+	s, err := lookup(key)
+	if err != nil {
+		return "", err
 	}
-	c.Save()
+	return s.get(&c.Settings), nil
 }
 
-func (c *Config) Get(key string, defaultValue interface{}) interface{} {
-	switch key {
-	case "db_path":
-		if c.Settings.DBPath != "" {
-			return c.Settings.DBPath
+// Remove deletes key from the config file so that it falls back to its
+// default value.
+func (c *Config) Remove(key string) error {
+	s, err := lookup(key)
+	if err != nil {
+		return err
+	}
+	d := defaults()
+	if err := s.set(&c.file, s.get(&d)); err != nil {
+		return fmt.Errorf("invalid value for %s: %w", key, err)
+	}
+	if c.sources[key] == SourceFile {
+		s.set(&c.Settings, s.get(&d))
+		c.sources[key] = SourceDefault
+	}
+	if err := c.validate(); err != nil {
+		return err
+	}
+	delete(c.explicit, key)
+	return c.Save()
+}
+
+// List returns every setting with its effective value and source.
+func (c *Config) List() []Value {
+	values := make([]Value, 0, len(settings))
+	for _, s := range settings {
+		values = append(values, Value{
+			Key:         s.key,
+			Value:       s.get(&c.Settings),
+			Source:      c.sources[s.key],
+			Description: s.description,
+		})
+	}
+	return values
+}
+
+// Keys returns the names of all settings.
+func Keys() []string {
+	keys := make([]string, 0, len(settings))
+	for _, s := range settings {
+		keys = append(keys, s.key)
+	}
+	return keys
+}
+
+// ActiveProfile returns the profile selected by the layered settings.
+func (c *Config) ActiveProfile() string {
+	if c.Settings.Profile != "" {
+		return c.Settings.Profile
+	}
+	return DefaultProfile
+}
+
+// ProfileSettings returns the effective settings with the overrides of the
+// named profile applied. Values given as flags take precedence over the
+// profile.
+func (c *Config) ProfileSettings(name string) (AppConfig, error) {
+	settings := c.Settings
+	if name == "" || name == DefaultProfile {
+		return settings, nil
+	}
+
+	profile, ok := c.Settings.Profiles[name]
+	if !ok {
+		return settings, fmt.Errorf("unknown profile %q", name)
+	}
+
+	flag := func(key string) bool { return c.sources[key] == SourceFlag }
+	if _, ok := profile["db_path"]; !ok && !flag("db_path") {
+		settings.DBPath = name + ".db"
+	}
+	if _, ok := profile["backup_dir"]; !ok && !flag("backup_dir") {
+		settings.BackupDir = filepath.Join(settings.BackupDir, name)
+	}
+	if err := profile.apply(&settings, flag); err != nil {
+		return settings, fmt.Errorf("invalid profile %q: %w", name, err)
+	}
+	return settings, nil
+}
+
+// AddProfile stores a new named profile and saves the config file.
+func (c *Config) AddProfile(name string, profile Profile) error {
+	if name == "" || name == DefaultProfile {
+		return fmt.Errorf("invalid profile name %q", name)
+	}
+	if _, ok := c.file.Profiles[name]; ok {
+		return fmt.Errorf("profile %q already exists", name)
+	}
+	d := defaults()
+	if err := profile.apply(&d, nil); err != nil {
+		return err
+	}
+	if c.file.Profiles == nil {
+		c.file.Profiles = make(map[string]Profile)
+	}
+	c.file.Profiles[name] = profile
+	c.Settings.Profiles = c.file.Profiles
+	return c.Save()
+}
+
+// SetProfile validates and stores a value for key in the named profile and
+// saves the config file.
+func (c *Config) SetProfile(name, key, value string) error {
+	profile, ok := c.file.Profiles[name]
+	if !ok {
+		return fmt.Errorf("unknown profile %q", name)
+	}
+	if err := profile.Set(key, value); err != nil {
+		return err
+	}
+	c.Settings.Profiles = c.file.Profiles
+	return c.Save()
+}
+
+// UseProfile makes the named profile the default and saves the config file.
+func (c *Config) UseProfile(name string) error {
+	return c.Set("profile", name)
+}
+
+// ProfileNames returns the default profile followed by the configured
+// profiles in alphabetical order.
+func (c *Config) ProfileNames() []string {
+	names := make([]string, 0, len(c.Settings.Profiles))
+	for name := range c.Settings.Profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return append([]string{DefaultProfile}, names...)
+}
+
+// ResolvePath turns a path relative to the data directory into an absolute
+// one. Files left in the config directory by earlier versions are still used
+// if they exist.
+func (c *Config) ResolvePath(path string) string {
+	if filepath.IsAbs(path) {
+		return path
+	}
+	legacy := filepath.Join(c.ConfigDir, path)
+	if _, err := os.Stat(legacy); err == nil {
+		return legacy
+	}
+	return filepath.Join(c.DataDir, path)
+}
+
+func (c *Config) validate() error {
+	if _, ok := c.Settings.Profiles[c.Settings.Profile]; c.Settings.Profile != "" && !ok {
+		return fmt.Errorf("unknown profile %q (source: %s)", c.Settings.Profile, c.sources["profile"])
+	}
+	for name, profile := range c.Settings.Profiles {
+		d := defaults()
+		if err := profile.apply(&d, nil); err != nil {
+			return fmt.Errorf("invalid profile %q: %w", name, err)
 		}
-	case "command_mode":
-		if c.Settings.CommandMode != "" {
-			return c.Settings.CommandMode
+	}
+	return nil
+}
+
+func validateCommandMode(mode string) error {
+	for _, m := range commandModes {
+		if m == mode {
+			return nil
+		}
+	}
+	return fmt.Errorf("command mode must be one of %s, got %q", strings.Join(commandModes, ", "), mode)
+}
+
+func validateTheme(theme string) error {
+	for _, t := range Themes {
+		if t == theme {
+			return nil
 		}
 	}
-	return defaultValue
+	return fmt.Errorf("theme must be one of %s, got %q", strings.Join(Themes, ", "), theme)
 }
 
-func (c *Config) Remove(key string) {
-	switch key {
-	case "db_path":
-		c.Settings.DBPath = ""
-	case "command_mode":
-		c.Settings.CommandMode = ""
+func xdgDir(env, fallback string) string {
+	if dir := os.Getenv(env); dir != "" && filepath.IsAbs(dir) {
+		return dir
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		home = os.Getenv("HOME")
 	}
-	c.Save()
+	return filepath.Join(home, fallback)
 }
@@ -0,0 +1,201 @@
+package config
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// setting describes a single config key and how to read, validate and write
+// it on an AppConfig.
+type setting struct {
+	key         string
+	env         string
+	description string
+	numeric     bool
+	omitEmpty   bool
+	get         func(*AppConfig) string
+	set         func(*AppConfig, string) error
+}
+
+var settings = []setting{
+	{
+		key:         "db_path",
+		env:         "GO_TIME_DB_PATH",
+		description: "Path to the SQLite database file, relative to the data directory",
+		get:         func(a *AppConfig) string { return a.DBPath },
+		set: func(a *AppConfig, v string) error {
+			if v == "" {
+				return fmt.Errorf("path cannot be empty")
+			}
+			a.DBPath = v
+			return nil
+		},
+	},
+	{
+		key:         "command_mode",
+		env:         "GO_TIME_COMMAND_MODE",
+		description: "Mode in which the application runs (cli, tui, help)",
+		get:         func(a *AppConfig) string { return a.CommandMode },
+		set: func(a *AppConfig, v string) error {
+			if err := validateCommandMode(v); err != nil {
+				return err
+			}
+			a.CommandMode = v
+			return nil
+		},
+	},
+	{
+		key:         "backup_dir",
+		env:         "GO_TIME_BACKUP_DIR",
+		description: "Directory for automatic daily backups, relative to the data directory",
+		get:         func(a *AppConfig) string { return a.BackupDir },
+		set: func(a *AppConfig, v string) error {
+			if v == "" {
+				return fmt.Errorf("directory cannot be empty")
+			}
+			a.BackupDir = v
+			return nil
+		},
+	},
+	{
+		key:         "backup_retention",
+		env:         "GO_TIME_BACKUP_RETENTION",
+		description: "Number of daily backups to keep (0 disables automatic backups)",
+		numeric:     true,
+		get:         func(a *AppConfig) string { return strconv.Itoa(a.BackupRetention) },
+		set: func(a *AppConfig, v string) error {
+			n, err := strconv.Atoi(v)
+			if err != nil || n < 0 {
+				return fmt.Errorf("must be a non-negative integer, got %q", v)
+			}
+			a.BackupRetention = n
+			return nil
+		},
+	},
+	{
+		key:         "timezone",
+		env:         "GO_TIME_TIMEZONE",
+		description: "Time zone used to display times and compute day boundaries (IANA name or Local)",
+		get:         func(a *AppConfig) string { return a.Timezone },
+		set: func(a *AppConfig, v string) error {
+			if v != "Local" {
+				if _, err := time.LoadLocation(v); err != nil {
+					return fmt.Errorf("unknown time zone %q", v)
+				}
+			}
+			a.Timezone = v
+			return nil
+		},
+	},
+	{
+		key:         "theme",
+		env:         "GO_TIME_THEME",
+		description: "Color theme of the TUI (dark, light, high-contrast)",
+		get:         func(a *AppConfig) string { return a.Theme },
+		set: func(a *AppConfig, v string) error {
+			if err := validateTheme(v); err != nil {
+				return err
+			}
+			a.Theme = v
+			return nil
+		},
+	},
+	{
+		key:         "idle_threshold",
+		env:         "GO_TIME_IDLE_THRESHOLD",
+		description: "Minutes without a heartbeat after which a running timer counts as idle (0 disables idle detection)",
+		numeric:     true,
+		get:         func(a *AppConfig) string { return strconv.Itoa(a.IdleThreshold) },
+		set: func(a *AppConfig, v string) error {
+			n, err := strconv.Atoi(v)
+			if err != nil || n < 0 {
+				return fmt.Errorf("must be a non-negative integer, got %q", v)
+			}
+			a.IdleThreshold = n
+			return nil
+		},
+	},
+	{
+		key:         "max_timer_hours",
+		env:         "GO_TIME_MAX_TIMER_HOURS",
+		description: "Hours after which a running timer is considered forgotten (0 disables the check)",
+		numeric:     true,
+		get:         func(a *AppConfig) string { return strconv.Itoa(a.MaxTimerHours) },
+		set: func(a *AppConfig, v string) error {
+			n, err := strconv.Atoi(v)
+			if err != nil || n < 0 {
+				return fmt.Errorf("must be a non-negative integer, got %q", v)
+			}
+			a.MaxTimerHours = n
+			return nil
+		},
+	},
+	{
+		key:         "strict_entries",
+		env:         "GO_TIME_STRICT_ENTRIES",
+		description: "Reject entries that overlap existing ones (true or false)",
+		get:         func(a *AppConfig) string { return strconv.FormatBool(a.StrictEntries) },
+		set: func(a *AppConfig, v string) error {
+			b, err := strconv.ParseBool(v)
+			if err != nil {
+				return fmt.Errorf("must be true or false, got %q", v)
+			}
+			a.StrictEntries = b
+			return nil
+		},
+	},
+	positive("pomodoro_work", "GO_TIME_POMODORO_WORK", "Length of a pomodoro work interval in minutes",
+		func(a *AppConfig) *int { return &a.PomodoroWork }),
+	positive("pomodoro_break", "GO_TIME_POMODORO_BREAK", "Length of a short pomodoro break in minutes",
+		func(a *AppConfig) *int { return &a.PomodoroBreak }),
+	positive("pomodoro_long_break", "GO_TIME_POMODORO_LONG_BREAK", "Length of a long pomodoro break in minutes",
+		func(a *AppConfig) *int { return &a.PomodoroLongBreak }),
+	positive("pomodoro_cycles", "GO_TIME_POMODORO_CYCLES", "Number of work intervals before a long pomodoro break",
+		func(a *AppConfig) *int { return &a.PomodoroCycles }),
+	{
+		key:         "profile",
+		env:         "GO_TIME_PROFILE",
+		description: "Profile used when none is selected with --profile",
+		omitEmpty:   true,
+		get:         func(a *AppConfig) string { return a.Profile },
+		set: func(a *AppConfig, v string) error {
+			if v == DefaultProfile {
+				v = ""
+			}
+			if _, ok := a.Profiles[v]; v != "" && !ok {
+				return fmt.Errorf("unknown profile %q", v)
+			}
+			a.Profile = v
+			return nil
+		},
+	},
+}
+
+// positive describes a numeric setting that must be greater than zero.
+func positive(key, env, description string, field func(*AppConfig) *int) setting {
+	return setting{
+		key:         key,
+		env:         env,
+		description: description,
+		numeric:     true,
+		get:         func(a *AppConfig) string { return strconv.Itoa(*field(a)) },
+		set: func(a *AppConfig, v string) error {
+			n, err := strconv.Atoi(v)
+			if err != nil || n <= 0 {
+				return fmt.Errorf("must be a positive integer, got %q", v)
+			}
+			*field(a) = n
+			return nil
+		},
+	}
+}
+
+func lookup(key string) (setting, error) {
+	for _, s := range settings {
+		if s.key == key {
+			return s, nil
+		}
+	}
+	return setting{}, fmt.Errorf("unknown config key %q", key)
+}
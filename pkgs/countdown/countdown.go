@@ -0,0 +1,176 @@
+// Package countdown provides a countdown component, the counterpart of the
+// stopwatch package.
+package countdown
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+var (
+	lastID int
+	idMtx  sync.Mutex
+)
+
+func nextID() int {
+	idMtx.Lock()
+	defer idMtx.Unlock()
+	lastID++
+	return lastID
+}
+
+// TickMsg is a message that is sent on every countdown tick.
+type TickMsg struct {
+	ID int
+}
+
+// StartStopMsg is sent when the countdown should start or stop.
+type StartStopMsg struct {
+	ID      int
+	running bool
+}
+
+// TimeoutMsg is sent once when the countdown reaches zero.
+type TimeoutMsg struct {
+	ID int
+}
+
+// Model for the countdown component. Like the stopwatch, the remaining time
+// is measured against the clock rather than counted in ticks.
+type Model struct {
+	// Timeout is the total time to count down from.
+	Timeout time.Duration
+
+	// d is the time counted down before the countdown was last started.
+	d       time.Duration
+	since   time.Time
+	id      int
+	running bool
+
+	// How long to wait before every tick. Defaults to 1 second.
+	Interval time.Duration
+
+	// Clock returns the current time. Defaults to time.Now.
+	Clock func() time.Time
+}
+
+// New creates a new countdown from timeout with a 1s interval.
+func New(timeout time.Duration) Model {
+	return Model{
+		Timeout:  timeout,
+		Interval: time.Second,
+		Clock:    time.Now,
+		id:       nextID(),
+	}
+}
+
+// ID returns the unique ID of the model.
+func (m Model) ID() int {
+	return m.id
+}
+
+// Init starts the countdown.
+func (m Model) Init() tea.Cmd {
+	return m.Start()
+}
+
+// Start starts the countdown.
+func (m Model) Start() tea.Cmd {
+	return tea.Batch(func() tea.Msg {
+		return StartStopMsg{ID: m.id, running: true}
+	}, tick(m.id, m.Interval))
+}
+
+// Stop stops the countdown.
+func (m Model) Stop() tea.Cmd {
+	return func() tea.Msg {
+		return StartStopMsg{ID: m.id, running: false}
+	}
+}
+
+// Run returns the countdown started without a tick of its own, for callers
+// that redraw it on a tick of their own and check Timedout.
+func (m Model) Run() Model {
+	return m.setRunning(true)
+}
+
+// Running returns true if the countdown is running or false if it is stopped.
+func (m Model) Running() bool {
+	return m.running && !m.Timedout()
+}
+
+// Update handles the countdown tick.
+func (m Model) Update(msg tea.Msg) (Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case StartStopMsg:
+		if msg.ID != m.id {
+			return m, nil
+		}
+		m = m.setRunning(msg.running)
+	case TickMsg:
+		if !m.running || msg.ID != m.id {
+			break
+		}
+		if m.Timedout() {
+			m = m.setRunning(false)
+			return m, func() tea.Msg { return TimeoutMsg{ID: m.id} }
+		}
+		return m, tick(m.id, m.Interval)
+	}
+
+	return m, nil
+}
+
+func (m Model) setRunning(running bool) Model {
+	if running == m.running {
+		return m
+	}
+	if running {
+		m.since = m.now()
+	} else {
+		m.d = m.elapsed()
+	}
+	m.running = running
+	return m
+}
+
+func (m Model) now() time.Time {
+	if m.Clock == nil {
+		return time.Now()
+	}
+	return m.Clock()
+}
+
+func (m Model) elapsed() time.Duration {
+	if !m.running {
+		return m.d
+	}
+	return m.d + m.now().Sub(m.since)
+}
+
+// Remaining returns the time left, which is never negative.
+func (m Model) Remaining() time.Duration {
+	return max(m.Timeout-m.elapsed(), 0)
+}
+
+// Timedout returns true once the countdown has reached zero.
+func (m Model) Timedout() bool {
+	return m.Remaining() == 0
+}
+
+// View of the countdown component.
+func (m Model) View() string {
+	d := m.Remaining().Round(time.Second)
+	minutes := d / time.Minute
+	seconds := (d % time.Minute) / time.Second
+	return fmt.Sprintf("%02dm%02ds", minutes, seconds)
+}
+
+func tick(id int, d time.Duration) tea.Cmd {
+	return tea.Tick(d, func(_ time.Time) tea.Msg {
+		return TickMsg{ID: id}
+	})
+}
@@ -0,0 +1,141 @@
+package entry
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"strings"
+)
+
+// change modifies a single entry within a bulk operation and reports
+// whether anything changed.
+type change func(ctx context.Context, tx *sql.Tx, id int) (bool, error)
+
+// bulk applies change to the entries with the given IDs in one transaction,
+// so that either all of them change or none. Every changed entry gets a
+// history record of action. It returns the number of entries changed.
+func bulk(ctx context.Context, db *sql.DB, ids []int, action string, c change) (int, error) {
+	tx, err := db.BeginTx(ctx, &sql.TxOptions{})
+	if err != nil {
+		return 0, fmt.Errorf("error starting transaction: %w", err)
+	}
+
+	defer func() {
+		if rbErr := tx.Rollback(); rbErr != nil && rbErr != sql.ErrTxDone {
+			log.Printf("Error rolling back transaction: %v", rbErr)
+		}
+	}()
+
+	changed := 0
+	for _, id := range ids {
+		// A deleted entry can only be recorded before it is gone.
+		if action == ActionDelete {
+			if err := recordHistory(ctx, tx, int64(id), action); err != nil {
+				return 0, err
+			}
+		}
+		ok, err := c(ctx, tx, id)
+		if err != nil {
+			return 0, err
+		}
+		if !ok {
+			continue
+		}
+		changed++
+		if action != ActionDelete {
+			if err := recordHistory(ctx, tx, int64(id), action); err != nil {
+				return 0, err
+			}
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("error committing transaction: %w", err)
+	}
+	return changed, nil
+}
+
+// TagEntries adds tags to the entries with the given IDs, creating tags that
+// do not exist yet. It returns the number of entries that got a new tag.
+func TagEntries(ctx context.Context, db *sql.DB, ids []int, tags []string) (int, error) {
+	return bulk(ctx, db, ids, ActionEdit, func(ctx context.Context, tx *sql.Tx, id int) (bool, error) {
+		added := false
+		for _, tag := range tags {
+			if _, err := tx.ExecContext(ctx, "INSERT OR IGNORE INTO tags (name) VALUES (?)", tag); err != nil {
+				return false, fmt.Errorf("error inserting tag: %w", err)
+			}
+			res, err := tx.ExecContext(ctx, "INSERT INTO entry_tags (entry_id, tag_id) SELECT ?, id FROM tags WHERE name = ? "+
+				"AND id NOT IN (SELECT tag_id FROM entry_tags WHERE entry_id = ?)", id, tag, id)
+			if err != nil {
+				return false, fmt.Errorf("error linking tag with entry %d: %w", id, err)
+			}
+			if n, _ := res.RowsAffected(); n > 0 {
+				added = true
+			}
+		}
+		return added, nil
+	})
+}
+
+// UntagEntries removes tags from the entries with the given IDs. It returns
+// the number of entries that lost a tag.
+func UntagEntries(ctx context.Context, db *sql.DB, ids []int, tags []string) (int, error) {
+	return bulk(ctx, db, ids, ActionEdit, func(ctx context.Context, tx *sql.Tx, id int) (bool, error) {
+		removed := false
+		for _, tag := range tags {
+			res, err := tx.ExecContext(ctx, "DELETE FROM entry_tags WHERE entry_id = ? AND tag_id IN (SELECT id FROM tags WHERE name = ?)", id, tag)
+			if err != nil {
+				return false, fmt.Errorf("error removing tag from entry %d: %w", id, err)
+			}
+			if n, _ := res.RowsAffected(); n > 0 {
+				removed = true
+			}
+		}
+		return removed, nil
+	})
+}
+
+// RenameEntries gives the entries with the given IDs a new name. It returns
+// the number of entries renamed.
+func RenameEntries(ctx context.Context, db *sql.DB, ids []int, name string) (int, error) {
+	if strings.TrimSpace(name) == "" {
+		return 0, fmt.Errorf("name cannot be empty")
+	}
+	return bulk(ctx, db, ids, ActionEdit, func(ctx context.Context, tx *sql.Tx, id int) (bool, error) {
+		res, err := tx.ExecContext(ctx, "UPDATE entries SET name = ? WHERE id = ? AND name != ?", name, id, name)
+		if err != nil {
+			return false, fmt.Errorf("error renaming entry %d: %w", id, err)
+		}
+		n, _ := res.RowsAffected()
+		return n > 0, nil
+	})
+}
+
+// MoveEntries moves the entries with the given IDs to project, or out of
+// any project if it is empty. It returns the number of entries moved.
+func MoveEntries(ctx context.Context, db *sql.DB, ids []int, project string) (int, error) {
+	project = strings.TrimSpace(project)
+	return bulk(ctx, db, ids, ActionEdit, func(ctx context.Context, tx *sql.Tx, id int) (bool, error) {
+		res, err := tx.ExecContext(ctx, "UPDATE entries SET project = ? WHERE id = ? AND project IS NOT ?", nullString(project), id, nullString(project))
+		if err != nil {
+			return false, fmt.Errorf("error moving entry %d: %w", id, err)
+		}
+		n, _ := res.RowsAffected()
+		return n > 0, nil
+	})
+}
+
+// DeleteEntries deletes the entries with the given IDs. It returns the
+// number of entries deleted.
+func DeleteEntries(ctx context.Context, db *sql.DB, ids []int) (int, error) {
+	return bulk(ctx, db, ids, ActionDelete, func(ctx context.Context, tx *sql.Tx, id int) (bool, error) {
+		if _, err := tx.ExecContext(ctx, "DELETE FROM entry_tags WHERE entry_id = ?", id); err != nil {
+			return false, fmt.Errorf("error deleting tags of entry %d: %w", id, err)
+		}
+		if _, err := tx.ExecContext(ctx, "DELETE FROM entries WHERE id = ?", id); err != nil {
+			return false, fmt.Errorf("error deleting entry %d: %w", id, err)
+		}
+		return true, nil
+	})
+}
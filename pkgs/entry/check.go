@@ -0,0 +1,177 @@
+package entry
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"slices"
+	"time"
+
+	"go-time/pkgs/util"
+)
+
+// strict rejects entries that overlap existing ones when they are created
+// or edited.
+var strict bool
+
+// SetStrict sets whether creating or editing an entry that overlaps another
+// one is an error.
+func SetStrict(enabled bool) {
+	strict = enabled
+}
+
+// Overlap is a period tracked by two entries at once.
+type Overlap struct {
+	First, Second Entry
+	Start, End    time.Time
+}
+
+// Duration returns the length of the overlap.
+func (o Overlap) Duration() time.Duration {
+	return o.End.Sub(o.Start)
+}
+
+// Gap is a period within a day that no entry tracks.
+type Gap struct {
+	Start, End time.Time
+}
+
+// Duration returns the length of the gap.
+func (g Gap) Duration() time.Duration {
+	return g.End.Sub(g.Start)
+}
+
+// OverlapError reports an entry that would overlap an existing one in
+// strict mode.
+type OverlapError struct {
+	Entry Entry
+}
+
+func (e OverlapError) Error() string {
+	return fmt.Sprintf("overlaps entry %d %q (%s - %s)", e.Entry.ID, e.Entry.Name,
+		util.FormatTime(e.Entry.StartTime), util.FormatTime(e.Entry.EndTime))
+}
+
+// FindOverlaps returns every pair of entries that track the same time, in
+// the order of their start times.
+func FindOverlaps(entries []Entry) []Overlap {
+	sorted := sortedByStart(entries)
+
+	var overlaps []Overlap
+	for i, first := range sorted {
+		for _, second := range sorted[i+1:] {
+			if !second.StartTime.Before(first.EndTime) {
+				break
+			}
+			end := first.EndTime
+			if second.EndTime.Before(end) {
+				end = second.EndTime
+			}
+			overlaps = append(overlaps, Overlap{First: first, Second: second, Start: second.StartTime, End: end})
+		}
+	}
+	return overlaps
+}
+
+// FindGaps returns the untracked periods of at least minGap between entries
+// on the same day, from the day of from to the day of to. Time before the
+// first and after the last entry of a day is not a gap.
+func FindGaps(entries []Entry, from, to time.Time, minGap time.Duration) []Gap {
+	sorted := sortedByStart(entries)
+
+	var gaps []Gap
+	for day := util.StartOfDay(from); !day.After(to); day = day.AddDate(0, 0, 1) {
+		next := day.AddDate(0, 0, 1)
+
+		var covered time.Time
+		for _, e := range sorted {
+			start, end := e.StartTime, e.EndTime
+			if !end.After(day) || !start.Before(next) {
+				continue
+			}
+			if start.Before(day) {
+				start = day
+			}
+			if end.After(next) {
+				end = next
+			}
+			if !covered.IsZero() && start.Sub(covered) >= minGap {
+				gaps = append(gaps, Gap{Start: covered, End: start})
+			}
+			if end.After(covered) {
+				covered = end
+			}
+		}
+	}
+	return gaps
+}
+
+func sortedByStart(entries []Entry) []Entry {
+	sorted := slices.Clone(entries)
+	slices.SortStableFunc(sorted, func(a, b Entry) int { return a.StartTime.Compare(b.StartTime) })
+	return sorted
+}
+
+// checkOverlap returns an OverlapError in strict mode if an entry from
+// start to end would overlap any entry other than the one with id exclude.
+func checkOverlap(ctx context.Context, tx *sql.Tx, start, end time.Time, exclude int) error {
+	if !strict {
+		return nil
+	}
+	return findOverlap(ctx, tx, start, end, exclude)
+}
+
+// findOverlap returns an OverlapError if an entry from start to end would
+// overlap any entry other than the one with id exclude.
+func findOverlap(ctx context.Context, tx *sql.Tx, start, end time.Time, exclude int) error {
+	row := tx.QueryRowContext(ctx, "SELECT "+entryColumns+" FROM entries WHERE end_time > ? AND start_time < ? AND id != ? ORDER BY start_time LIMIT 1",
+		start.UTC(), end.UTC(), exclude)
+	other, err := scanEntry(row)
+	if err == sql.ErrNoRows {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("error checking for overlapping entries: %w", err)
+	}
+	return OverlapError{Entry: other}
+}
+
+// TrimOverlap resolves an overlap by ending the first entry where the
+// second one starts. Entries that start at the same time, or of which one
+// lies entirely within the other, cannot be trimmed and have to be merged
+// instead.
+func TrimOverlap(ctx context.Context, db *sql.DB, o Overlap) error {
+	if !o.Second.StartTime.After(o.First.StartTime) {
+		return fmt.Errorf("entries %d and %d start at the same time, merge them instead", o.First.ID, o.Second.ID)
+	}
+	if !o.Second.EndTime.After(o.First.EndTime) {
+		return fmt.Errorf("entry %d lies within entry %d, merge them instead", o.Second.ID, o.First.ID)
+	}
+
+	tx, err := db.BeginTx(ctx, &sql.TxOptions{})
+	if err != nil {
+		return fmt.Errorf("error starting transaction: %w", err)
+	}
+
+	defer func() {
+		if rbErr := tx.Rollback(); rbErr != nil && rbErr != sql.ErrTxDone {
+			log.Printf("Error rolling back transaction: %v", rbErr)
+		}
+	}()
+
+	end := o.Second.StartTime
+	_, err = tx.ExecContext(ctx, "UPDATE entries SET end_time = ?, end_offset = ? WHERE id = ?",
+		end.UTC(), util.ZoneOffset(end), o.First.ID)
+	if err != nil {
+		return fmt.Errorf("error trimming entry %d: %w", o.First.ID, err)
+	}
+	if err := recordHistory(ctx, tx, int64(o.First.ID), ActionEdit); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("error committing transaction: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,64 @@
+package entry
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+	"time"
+)
+
+// Filter selects entries. Entries match if they overlap the range from From
+// to To, have the tag Tag, a name matching the pattern Name, in which *
+// stands for any text, and belong to the project Project. Zero fields do not
+// restrict the entries.
+type Filter struct {
+	From, To time.Time
+	Tag      string
+	Name     string
+	Project  string
+}
+
+// IsZero reports whether the filter matches every entry.
+func (f Filter) IsZero() bool {
+	return f.From.IsZero() && f.To.IsZero() && f.Tag == "" && f.Name == "" && f.Project == ""
+}
+
+// where returns the SQL condition for the filter and its arguments.
+func (f Filter) where() (string, []any) {
+	conditions := []string{"1 = 1"}
+	var args []any
+	if !f.From.IsZero() {
+		conditions = append(conditions, "end_time > ?")
+		args = append(args, f.From.UTC())
+	}
+	if !f.To.IsZero() {
+		conditions = append(conditions, "start_time < ?")
+		args = append(args, f.To.UTC())
+	}
+	if f.Tag != "" {
+		conditions = append(conditions, "id IN (SELECT et.entry_id FROM entry_tags et INNER JOIN tags t ON t.id = et.tag_id WHERE t.name = ?)")
+		args = append(args, f.Tag)
+	}
+	if f.Name != "" {
+		conditions = append(conditions, `name LIKE ? ESCAPE '\'`)
+		args = append(args, likePattern(f.Name))
+	}
+	if f.Project != "" {
+		conditions = append(conditions, "project = ?")
+		args = append(args, f.Project)
+	}
+	return strings.Join(conditions, " AND "), args
+}
+
+// likePattern turns a pattern in which * stands for any text into a LIKE
+// pattern, escaping the characters LIKE treats specially.
+func likePattern(pattern string) string {
+	r := strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`, "*", "%")
+	return r.Replace(pattern)
+}
+
+// ReadEntriesMatching returns the entries matching f, ordered by start time.
+func ReadEntriesMatching(ctx context.Context, db *sql.DB, f Filter) ([]Entry, error) {
+	where, args := f.where()
+	return queryEntries(ctx, db, "SELECT "+entryColumns+" FROM entries WHERE "+where+" ORDER BY start_time", args...)
+}
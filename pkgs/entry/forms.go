@@ -10,103 +10,141 @@ import (
 	"github.com/charmbracelet/huh"
 	"github.com/charmbracelet/huh/spinner"
 
+	"go-time/pkgs/tag"
 	"go-time/pkgs/util"
 )
 
+// FormResult holds the validated values of a completed entry form.
+type FormResult struct {
+	Name        string
+	Description string
+	StartTime   time.Time
+	EndTime     time.Time
+	Tags        []string
+}
+
 func Form(tags []string) *huh.Form {
-	options := util.CreateTagOptions(tags)
-	return huh.NewForm(
-		huh.NewGroup(
-			huh.NewInput().
-				Key("name").
-				Title("Name"),
-			huh.NewInput().
-				Key("startTime").
-				Title("Start Time (YYYY-MM-DD HH:MM:SS)"),
-			huh.NewInput().
-				Key("endTime").
-				Title("End Time (YYYY-MM-DD HH:MM:SS)"),
-			huh.NewMultiSelect[string]().
-				Key("tags").
-				Title("Tags").
-				Options(options...).
-				Limit(3).
-				Value(&tags),
-		),
-	)
+	return newForm("", "", "", "", nil, tags)
 }
 
 func EditForm(entry Entry, tags []string) *huh.Form {
+	return newForm(entry.Name, entry.Description.String, util.FormatTime(entry.StartTime), util.FormatTime(entry.EndTime),
+		tag.Names(entry.Tags), tags)
+}
+
+// ResultForm rebuilds a form from the values of a completed one, so the user
+// can correct them after saving failed.
+func ResultForm(result FormResult, tags []string) *huh.Form {
+	return newForm(result.Name, result.Description, util.FormatTime(result.StartTime), util.FormatTime(result.EndTime),
+		result.Tags, tags)
+}
+
+func newForm(name, description, startTime, endTime string, selected, tags []string) *huh.Form {
 	options := util.CreateTagOptions(tags)
 	return huh.NewForm(
 		huh.NewGroup(
 			huh.NewInput().
 				Key("name").
 				Title("Name").
-				Value(&entry.Name),
+				Value(&name).
+				Validate(util.ValidateNotEmpty),
+			huh.NewInput().
+				Key("description").
+				Title("Description").
+				Value(&description),
 			huh.NewInput().
 				Key("startTime").
 				Title("Start Time (YYYY-MM-DD HH:MM:SS)").
-				Value(util.TimePtrToStringPtr(&entry.StartTime)), // Convert *time.Time to *string
+				Value(&startTime).
+				Validate(util.ValidateTime),
 			huh.NewInput().
 				Key("endTime").
 				Title("End Time (YYYY-MM-DD HH:MM:SS)").
-				Value(util.TimePtrToStringPtr(&entry.EndTime)), // Convert *time.Time to *string
+				Value(&endTime).
+				Validate(func(s string) error {
+					end, err := util.ParseTime(s)
+					if err != nil {
+						return err
+					}
+					if start, err := util.ParseTime(startTime); err == nil && end.Before(start) {
+						return fmt.Errorf("end time cannot be before start time")
+					}
+					return nil
+				}),
 			huh.NewMultiSelect[string]().
 				Key("tags").
 				Title("Tags").
 				Options(options...).
 				Limit(3).
-				Value(&tags),
+				Value(&selected),
 		),
 	)
 }
 
-func HandleForm(ctx context.Context, db *sql.DB, tags []string) {
-
-	// Initialize the form with the tags
-	form := Form(tags)
-	if err := form.Run(); err != nil {
-		log.Printf("Error running entry form: %v", err)
-		return
+// ReadForm extracts and validates the values of a completed entry form.
+func ReadForm(form *huh.Form) (FormResult, error) {
+	result := FormResult{
+		Name:        form.GetString("name"),
+		Description: form.GetString("description"),
 	}
 
-	// Extract data from the form
-	name := form.GetString("name")
-	startTimeStr := form.GetString("startTime")
-	endTimeStr := form.GetString("endTime")
 	tags, ok := form.Get("tags").([]string)
 	if !ok {
-		fmt.Println("Error: tags is not of type []string")
-		return
+		return result, fmt.Errorf("tags is not of type []string")
 	}
+	result.Tags = tags
 
-	// Convert string times to time.Time objects
-	startTime, err := time.Parse("2006-01-02 15:04:05", startTimeStr)
-	if err != nil {
-		fmt.Printf("Error parsing start time: %v\n", err)
-		return
+	var err error
+	if result.StartTime, err = util.ParseTime(form.GetString("startTime")); err != nil {
+		return result, fmt.Errorf("error parsing start time: %w", err)
+	}
+	if result.EndTime, err = util.ParseTime(form.GetString("endTime")); err != nil {
+		return result, fmt.Errorf("error parsing end time: %w", err)
 	}
-	endTime, err := time.Parse("2006-01-02 15:04:05", endTimeStr)
+
+	return result, validate(result.Name, result.StartTime, result.EndTime)
+}
+
+// SaveForm creates a new entry from result, or updates the entry with the
+// given ID if it is not zero.
+func SaveForm(ctx context.Context, db *sql.DB, id int, result FormResult) error {
+	if id != 0 {
+		return EditEntry(ctx, db, id, result.Name, result.Description, result.StartTime, result.EndTime, result.Tags)
+	}
+
+	tx, err := db.BeginTx(ctx, &sql.TxOptions{})
 	if err != nil {
-		fmt.Printf("Error parsing end time: %v\n", err)
+		return fmt.Errorf("error beginning transaction: %w", err)
+	}
+	if _, err := CreateEntry(ctx, tx, result.Name, result.Description, "", result.StartTime, result.EndTime, result.Tags); err != nil {
+		tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
+
+func HandleForm(ctx context.Context, db *sql.DB, tags []string) {
+
+	// Initialize the form with the tags
+	form := Form(tags)
+	if err := form.Run(); err != nil {
+		log.Printf("Error running entry form: %v", err)
 		return
 	}
 
-	tx, err := db.Begin()
+	// Extract data from the form
+	result, err := ReadForm(form)
 	if err != nil {
-		log.Printf("Error beginning transaction: %v", err)
+		fmt.Println("Error:", err)
 		return
 	}
 
 	spinner := spinner.New().Title("Saving entry...")
 	err = spinner.Action(func() {
-		if err := CreateEntry(ctx, tx, name, startTime, endTime, tags); err != nil {
+		if err := SaveForm(ctx, db, 0, result); err != nil {
 			log.Printf("Error saving entry: %v", err)
-			tx.Rollback() // Ensure rollback on error
 		} else {
-			fmt.Println("Entry saved successfully for:", name)
-			tx.Commit() // Commit only on success
+			fmt.Println("Entry saved successfully for:", result.Name)
 		}
 	}).Run()
 
@@ -114,3 +152,43 @@ func HandleForm(ctx context.Context, db *sql.DB, tags []string) {
 		fmt.Println("Error during spinner action: ", err)
 	}
 }
+
+// SplitForm asks where to split e, suggesting the middle of it.
+func SplitForm(e Entry) *huh.Form {
+	at := util.FormatTime(e.StartTime.Add(e.Duration() / 2).Truncate(time.Minute))
+	return huh.NewForm(
+		huh.NewGroup(
+			huh.NewNote().Title("Split entry").Description(fmt.Sprintf("Split entry %d %q (%s - %s) into two.",
+				e.ID, e.Name, util.FormatTime(e.StartTime), util.FormatTime(e.EndTime))),
+			huh.NewInput().Key("at").Title("Split At (YYYY-MM-DD HH:MM:SS)").Value(&at).
+				Validate(func(s string) error {
+					_, err := readSplit(e, s)
+					return err
+				}),
+		),
+	)
+}
+
+// ReadSplitForm returns the time entered in a completed split form for e.
+func ReadSplitForm(form *huh.Form, e Entry) (time.Time, error) {
+	return readSplit(e, form.GetString("at"))
+}
+
+func readSplit(e Entry, s string) (time.Time, error) {
+	at, err := util.ParseTime(s)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return at, ValidateSplit(e, at)
+}
+
+// ConfirmBulk asks whether to apply a bulk change, described by verb, to n
+// entries.
+func ConfirmBulk(verb string, n int) (bool, error) {
+	confirmed := false
+	prompt := huh.NewConfirm().Title(fmt.Sprintf("%s %d time entries?", verb, n)).Value(&confirmed)
+	if err := prompt.Run(); err != nil {
+		return false, fmt.Errorf("error running confirmation: %w", err)
+	}
+	return confirmed, nil
+}
@@ -0,0 +1,188 @@
+package entry
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"go-time/pkgs/util"
+)
+
+const (
+	ActionCreate = "create"
+	ActionEdit   = "edit"
+	ActionDelete = "delete"
+)
+
+// HistoryRecord is a snapshot of an entry taken whenever it is created,
+// edited or deleted.
+type HistoryRecord struct {
+	ID          int            `json:"id"`
+	EntryID     int            `json:"entry_id"`
+	Action      string         `json:"action"`
+	ChangedAt   time.Time      `json:"changed_at"`
+	Name        string         `json:"name"`
+	Description sql.NullString `json:"description"`
+	Project     sql.NullString `json:"project"`
+	StartTime   time.Time      `json:"start_time"`
+	EndTime     time.Time      `json:"end_time"`
+	Tags        []string       `json:"tags"`
+}
+
+// Change describes a single field that differs between two history records.
+type Change struct {
+	Field string
+	From  string
+	To    string
+}
+
+func ReadHistory(ctx context.Context, db *sql.DB, entryID int) ([]HistoryRecord, error) {
+	const query = `
+    SELECT id, entry_id, action, changed_at, name, description, project, start_time, end_time, tags
+    FROM entry_history
+    WHERE entry_id = ?
+    ORDER BY changed_at, id`
+
+	rows, err := db.QueryContext(ctx, query, entryID)
+	if err != nil {
+		return nil, fmt.Errorf("error querying entry history: %w", err)
+	}
+	defer rows.Close()
+
+	var records []HistoryRecord
+	for rows.Next() {
+		var record HistoryRecord
+		var tags string
+		if err := rows.Scan(&record.ID, &record.EntryID, &record.Action, &record.ChangedAt, &record.Name,
+			&record.Description, &record.Project, &record.StartTime, &record.EndTime, &tags); err != nil {
+			return nil, fmt.Errorf("error scanning entry history row: %w", err)
+		}
+		if tags != "" {
+			if err := json.Unmarshal([]byte(tags), &record.Tags); err != nil {
+				return nil, fmt.Errorf("error reading tags of entry history row %d: %w", record.ID, err)
+			}
+		}
+		records = append(records, record)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating over entry history rows: %w", err)
+	}
+	return records, nil
+}
+
+// Diff returns the fields that changed between prev and r. When prev is nil
+// every field of r is reported as newly set.
+func (r HistoryRecord) Diff(prev *HistoryRecord) []Change {
+	var from HistoryRecord
+	if prev != nil {
+		from = *prev
+	}
+
+	fields := []struct {
+		name     string
+		from, to string
+	}{
+		{"name", from.Name, r.Name},
+		{"description", from.Description.String, r.Description.String},
+		{"project", from.Project.String, r.Project.String},
+		{"start time", formatHistoryTime(from.StartTime), formatHistoryTime(r.StartTime)},
+		{"end time", formatHistoryTime(from.EndTime), formatHistoryTime(r.EndTime)},
+		{"tags", formatHistoryTags(from.Tags), formatHistoryTags(r.Tags)},
+	}
+
+	var changes []Change
+	for _, f := range fields {
+		if f.from != f.to {
+			changes = append(changes, Change{Field: f.name, From: f.from, To: f.to})
+		}
+	}
+	return changes
+}
+
+func formatHistoryTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return util.FormatTime(t)
+}
+
+// formatHistoryTags lists tags separated by commas, quoting tags that
+// contain one so that they cannot be mistaken for two.
+func formatHistoryTags(tags []string) string {
+	names := make([]string, len(tags))
+	for i, tag := range tags {
+		if strings.Contains(tag, ",") {
+			tag = strconv.Quote(tag)
+		}
+		names[i] = tag
+	}
+	return strings.Join(names, ", ")
+}
+
+func recordHistory(ctx context.Context, tx *sql.Tx, entryID int64, action string) error {
+	var name string
+	var description, project sql.NullString
+	var startTime, endTime time.Time
+	err := tx.QueryRowContext(ctx, "SELECT name, description, project, start_time, end_time FROM entries WHERE id = ?", entryID).
+		Scan(&name, &description, &project, &startTime, &endTime)
+	if err != nil {
+		return fmt.Errorf("error reading entry %d for history: %w", entryID, err)
+	}
+
+	tags, err := fetchTagsForEntry(ctx, tx, entryID)
+	if err != nil {
+		return fmt.Errorf("error fetching tags for entry history: %w", err)
+	}
+	// Tags are stored as a JSON array since tag names may contain commas.
+	tagsJSON := ""
+	if len(tags) > 0 {
+		b, err := json.Marshal(tags)
+		if err != nil {
+			return fmt.Errorf("error encoding tags for entry history: %w", err)
+		}
+		tagsJSON = string(b)
+	}
+
+	_, err = tx.ExecContext(ctx, `
+    INSERT INTO entry_history (entry_id, action, changed_at, name, description, project, start_time, end_time, tags)
+    VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		entryID, action, time.Now().UTC(), name, description, project, startTime.UTC(), endTime.UTC(), tagsJSON)
+	if err != nil {
+		return fmt.Errorf("error recording entry history: %w", err)
+	}
+	return nil
+}
+
+func fetchTagsForEntry(ctx context.Context, tx *sql.Tx, entryID int64) ([]string, error) {
+	var tags []string
+	query := `
+    SELECT t.name
+    FROM tags t
+    INNER JOIN entry_tags et ON t.id = et.tag_id
+    WHERE et.entry_id = ?
+    ORDER BY t.name`
+
+	rows, err := tx.QueryContext(ctx, query, entryID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var tag string
+		if err := rows.Scan(&tag); err != nil {
+			return nil, err
+		}
+		tags = append(tags, tag)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return tags, nil
+}
@@ -5,24 +5,69 @@ import (
 	"database/sql"
 	"fmt"
 	"log"
+	"strings"
 	"time"
 
 	_ "github.com/mattn/go-sqlite3"
 	"go-time/pkgs/tag"
+	"go-time/pkgs/util"
 )
 
 type Entry struct {
 	ID          int            `json:"id"`
 	Name        string         `json:"name"`
 	Description sql.NullString `json:"description"`
+	Project     sql.NullString `json:"project"`
 	StartTime   time.Time      `json:"start_time"`
 	EndTime     time.Time      `json:"end_time"`
 	Tags        []tag.Tag      `json:"tags"`
+
+	// StartOffset and EndOffset are the offsets from UTC in seconds of the
+	// zone the times were recorded in. The times themselves are stored in UTC.
+	StartOffset int `json:"start_offset"`
+	EndOffset   int `json:"end_offset"`
+}
+
+const entryColumns = "id, name, description, project, start_time, end_time, start_offset, end_offset"
+
+// qualifiedColumns returns columns with each column prefixed by table, for
+// queries that join other tables.
+func qualifiedColumns(table, columns string) string {
+	fields := strings.Split(columns, ", ")
+	for i, field := range fields {
+		fields[i] = table + "." + field
+	}
+	return strings.Join(fields, ", ")
+}
+
+type scanner interface {
+	Scan(dest ...any) error
+}
+
+func scanEntry(row scanner) (Entry, error) {
+	var entry Entry
+	err := row.Scan(&entry.ID, &entry.Name, &entry.Description, &entry.Project, &entry.StartTime, &entry.EndTime, &entry.StartOffset, &entry.EndOffset)
+	return entry, err
+}
+
+// Duration returns how long the entry lasted.
+func (e Entry) Duration() time.Duration {
+	return e.EndTime.Sub(e.StartTime)
 }
 
 func ReadEntries(ctx context.Context, db *sql.DB) ([]Entry, error) {
-	const query = "SELECT id, name, description, start_time, end_time FROM entries"
-	rows, err := db.QueryContext(ctx, query)
+	return queryEntries(ctx, db, "SELECT "+entryColumns+" FROM entries")
+}
+
+// ReadEntriesBetween returns the entries that overlap the range from start
+// to end, ordered by start time.
+func ReadEntriesBetween(ctx context.Context, db *sql.DB, start, end time.Time) ([]Entry, error) {
+	const query = "SELECT " + entryColumns + " FROM entries WHERE end_time > ? AND start_time < ? ORDER BY start_time"
+	return queryEntries(ctx, db, query, start.UTC(), end.UTC())
+}
+
+func queryEntries(ctx context.Context, db *sql.DB, query string, args ...any) ([]Entry, error) {
+	rows, err := db.QueryContext(ctx, query, args...)
 	if err != nil {
 		log.Printf("Error querying entries: %v", err)
 		return nil, fmt.Errorf("error querying entries: %w", err)
@@ -31,8 +76,8 @@ func ReadEntries(ctx context.Context, db *sql.DB) ([]Entry, error) {
 
 	var entries []Entry
 	for rows.Next() {
-		var entry Entry
-		if err := rows.Scan(&entry.ID, &entry.Name, &entry.Description, &entry.StartTime, &entry.EndTime); err != nil {
+		entry, err := scanEntry(rows)
+		if err != nil {
 			log.Printf("Error scanning time entry row: %v", err)
 			return nil, fmt.Errorf("error scanning time entry row: %w", err)
 		}
@@ -47,46 +92,76 @@ func ReadEntries(ctx context.Context, db *sql.DB) ([]Entry, error) {
 	return entries, nil
 }
 
-func CreateEntry(ctx context.Context, tx *sql.Tx, name string, start, end time.Time, tags []string) error {
-	if name == "" {
-		return fmt.Errorf("name cannot be empty")
+// CreateEntry inserts a new entry within tx and returns its ID.
+func CreateEntry(ctx context.Context, tx *sql.Tx, name, description, project string, start, end time.Time, tags []string) (int, error) {
+	if err := validate(name, start, end); err != nil {
+		return 0, err
 	}
-	if end.Before(start) {
-		return fmt.Errorf("end time cannot be before start time")
+	if err := checkOverlap(ctx, tx, start, end, 0); err != nil {
+		return 0, err
 	}
 
-	res, err := tx.ExecContext(ctx, "INSERT INTO entries (name,  start_time, end_time) VALUES (?, ?, ?)", name, start, end)
+	res, err := tx.ExecContext(ctx, "INSERT INTO entries (name, description, project, start_time, end_time, start_offset, end_offset) VALUES (?, ?, ?, ?, ?, ?, ?)",
+		name, nullString(description), nullString(project), start.UTC(), end.UTC(), util.ZoneOffset(start), util.ZoneOffset(end))
 	if err != nil {
-		return fmt.Errorf("error executing statement: %w", err)
+		return 0, fmt.Errorf("error executing statement: %w", err)
 	}
 
 	entryID, err := res.LastInsertId()
 	if err != nil {
-		return fmt.Errorf("error getting last insert ID: %w", err)
+		return 0, fmt.Errorf("error getting last insert ID: %w", err)
 	}
 
 	for _, tag := range tags {
 		var tagID int
 		_, err = tx.ExecContext(ctx, "INSERT OR IGNORE INTO tags (name) VALUES (?)", tag)
 		if err != nil {
-			return fmt.Errorf("error inserting tag: %w", err)
+			return 0, fmt.Errorf("error inserting tag: %w", err)
 		}
 
 		err = tx.QueryRowContext(ctx, "SELECT id FROM tags WHERE name = ?", tag).Scan(&tagID)
 		if err != nil {
-			return fmt.Errorf("error getting tag ID: %w", err)
+			return 0, fmt.Errorf("error getting tag ID: %w", err)
 		}
 
 		_, err = tx.ExecContext(ctx, "INSERT INTO entry_tags (entry_id, tag_id) VALUES (?, ?)", entryID, tagID)
 		if err != nil {
-			return fmt.Errorf("error linking tag with entry: %w", err)
+			return 0, fmt.Errorf("error linking tag with entry: %w", err)
 		}
 	}
 
-	return nil
+	if err := recordHistory(ctx, tx, entryID, ActionCreate); err != nil {
+		return 0, err
+	}
+	return int(entryID), nil
+}
+
+// GetEntry returns a single entry together with its tags. Its times are in
+// the zones they were recorded in.
+func GetEntry(ctx context.Context, db *sql.DB, id int) (Entry, error) {
+	entry, err := scanEntry(db.QueryRowContext(ctx, "SELECT "+entryColumns+" FROM entries WHERE id = ?", id))
+	if err == sql.ErrNoRows {
+		return Entry{}, fmt.Errorf("entry %d not found", id)
+	}
+	if err != nil {
+		return Entry{}, fmt.Errorf("error reading entry: %w", err)
+	}
+	entry.StartTime = util.InOffset(entry.StartTime, entry.StartOffset)
+	entry.EndTime = util.InOffset(entry.EndTime, entry.EndOffset)
+
+	tags, err := tag.GetTagsForEntry(ctx, db, id)
+	if err != nil {
+		return Entry{}, fmt.Errorf("error reading tags for entry: %w", err)
+	}
+	entry.Tags = tags
+	return entry, nil
 }
 
-func EditEntry(ctx context.Context, db *sql.DB, id int, name, description string, tags []string) error {
+func EditEntry(ctx context.Context, db *sql.DB, id int, name, description string, start, end time.Time, tags []string) error {
+	if err := validate(name, start, end); err != nil {
+		return err
+	}
+
 	tx, err := db.BeginTx(ctx, &sql.TxOptions{})
 	if err != nil {
 		return fmt.Errorf("error starting transaction: %w", err)
@@ -100,7 +175,32 @@ func EditEntry(ctx context.Context, db *sql.DB, id int, name, description string
 		}
 	}()
 
-	if _, err = tx.ExecContext(ctx, "UPDATE entries SET name = ?, description = ? WHERE id = ?", name, description, id); err != nil {
+	if err = checkOverlap(ctx, tx, start, end, id); err != nil {
+		return err
+	}
+
+	// Times that did not change keep the zone they were recorded in.
+	var oldStart, oldEnd time.Time
+	var startOffset, endOffset int
+	err = tx.QueryRowContext(ctx, "SELECT start_time, end_time, start_offset, end_offset FROM entries WHERE id = ?", id).
+		Scan(&oldStart, &oldEnd, &startOffset, &endOffset)
+	if err == sql.ErrNoRows {
+		err = fmt.Errorf("entry %d not found", id)
+		return err
+	}
+	if err != nil {
+		return fmt.Errorf("error reading entry: %w", err)
+	}
+	if !start.Equal(oldStart) {
+		startOffset = util.ZoneOffset(start)
+	}
+	if !end.Equal(oldEnd) {
+		endOffset = util.ZoneOffset(end)
+	}
+
+	_, err = tx.ExecContext(ctx, "UPDATE entries SET name = ?, description = ?, start_time = ?, end_time = ?, start_offset = ?, end_offset = ? WHERE id = ?",
+		name, nullString(description), start.UTC(), end.UTC(), startOffset, endOffset, id)
+	if err != nil {
 		return fmt.Errorf("error executing update statement: %w", err)
 	}
 
@@ -127,6 +227,10 @@ func EditEntry(ctx context.Context, db *sql.DB, id int, name, description string
 		}
 	}
 
+	if err = recordHistory(ctx, tx, int64(id), ActionEdit); err != nil {
+		return err
+	}
+
 	if err := tx.Commit(); err != nil {
 		return fmt.Errorf("error committing transaction: %w", err)
 	}
@@ -148,6 +252,10 @@ func DeleteEntry(ctx context.Context, db *sql.DB, id int) error {
 		}
 	}()
 
+	if err = recordHistory(ctx, tx, int64(id), ActionDelete); err != nil {
+		return err
+	}
+
 	statement, err := tx.PrepareContext(ctx, "DELETE FROM entries WHERE id = ?")
 	if err != nil {
 		return fmt.Errorf("error preparing delete statement: %w", err)
@@ -168,7 +276,7 @@ func DeleteEntry(ctx context.Context, db *sql.DB, id int) error {
 func GetEntriesByTag(db *sql.DB, tagName string) ([]Entry, error) {
 	var entries []Entry
 	query := `
-    SELECT e.id, e.name, e.description, e.start_time, e.end_time
+    SELECT ` + qualifiedColumns("e", entryColumns) + `
     FROM entries e
     INNER JOIN entry_tags et ON e.id = et.entry_id
     INNER JOIN tags t ON et.tag_id = t.id
@@ -181,8 +289,8 @@ func GetEntriesByTag(db *sql.DB, tagName string) ([]Entry, error) {
 	defer rows.Close()
 
 	for rows.Next() {
-		var entry Entry
-		if err := rows.Scan(&entry.ID, &entry.Name, &entry.Description, &entry.StartTime, &entry.EndTime); err != nil {
+		entry, err := scanEntry(rows)
+		if err != nil {
 			return nil, fmt.Errorf("error scanning entry: %w", err)
 		}
 		entries = append(entries, entry)
@@ -214,3 +322,17 @@ func AddTagsToEntry(db *sql.DB, entryID int, tags []string) error {
 	}
 	return nil
 }
+
+func validate(name string, start, end time.Time) error {
+	if name == "" {
+		return fmt.Errorf("name cannot be empty")
+	}
+	if end.Before(start) {
+		return fmt.Errorf("end time cannot be before start time")
+	}
+	return nil
+}
+
+func nullString(s string) sql.NullString {
+	return sql.NullString{String: s, Valid: s != ""}
+}
@@ -0,0 +1,66 @@
+package entry
+
+import (
+	"context"
+	"database/sql"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"go-time/db"
+)
+
+func newTestDB(t *testing.T) *sql.DB {
+	t.Helper()
+	database, err := db.InitDB(filepath.Join(t.TempDir(), "go-time.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { database.Close() })
+	return database
+}
+
+func createTestEntry(t *testing.T, database *sql.DB, name, project string, start time.Time, tags []string) int {
+	t.Helper()
+	ctx := context.Background()
+	tx, err := database.BeginTx(ctx, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tx.Rollback()
+	id, err := CreateEntry(ctx, tx, name, "", project, start, start.Add(time.Hour), tags)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := tx.Commit(); err != nil {
+		t.Fatal(err)
+	}
+	return id
+}
+
+func TestGetEntriesByTag(t *testing.T) {
+	database := newTestDB(t)
+	start := time.Date(2026, 10, 19, 9, 0, 0, 0, time.UTC)
+	id := createTestEntry(t, database, "review", "go-time", start, []string{"work", "code"})
+	createTestEntry(t, database, "lunch", "", start.Add(2*time.Hour), []string{"break"})
+
+	entries, err := GetEntriesByTag(database, "work")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 {
+		t.Fatalf("GetEntriesByTag returned %d entries, want 1", len(entries))
+	}
+	e := entries[0]
+	if e.ID != id || e.Name != "review" || e.Project.String != "go-time" || !e.StartTime.Equal(start) {
+		t.Errorf("GetEntriesByTag returned %+v, want entry %d of project go-time starting at %v", e, id, start)
+	}
+
+	entries, err = GetEntriesByTag(database, "unused")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 0 {
+		t.Errorf("GetEntriesByTag returned %d entries for an unused tag, want none", len(entries))
+	}
+}
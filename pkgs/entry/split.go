@@ -0,0 +1,167 @@
+package entry
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"slices"
+	"strings"
+	"time"
+
+	"go-time/pkgs/util"
+)
+
+// SplitEntry ends the entry with the given ID at at and records the rest of
+// it as a new entry with the same name, description, project and tags. It returns
+// the ID of the new entry.
+func SplitEntry(ctx context.Context, db *sql.DB, id int, at time.Time) (int, error) {
+	tx, err := db.BeginTx(ctx, &sql.TxOptions{})
+	if err != nil {
+		return 0, fmt.Errorf("error starting transaction: %w", err)
+	}
+
+	defer func() {
+		if rbErr := tx.Rollback(); rbErr != nil && rbErr != sql.ErrTxDone {
+			log.Printf("Error rolling back transaction: %v", rbErr)
+		}
+	}()
+
+	e, err := scanEntry(tx.QueryRowContext(ctx, "SELECT "+entryColumns+" FROM entries WHERE id = ?", id))
+	if err == sql.ErrNoRows {
+		return 0, fmt.Errorf("entry %d not found", id)
+	}
+	if err != nil {
+		return 0, fmt.Errorf("error reading entry: %w", err)
+	}
+	e.StartTime = util.InOffset(e.StartTime, e.StartOffset)
+	e.EndTime = util.InOffset(e.EndTime, e.EndOffset)
+	if err := ValidateSplit(e, at); err != nil {
+		return 0, err
+	}
+
+	tags, err := fetchTagsForEntry(ctx, tx, int64(id))
+	if err != nil {
+		return 0, fmt.Errorf("error reading tags for entry: %w", err)
+	}
+
+	_, err = tx.ExecContext(ctx, "UPDATE entries SET end_time = ?, end_offset = ? WHERE id = ?",
+		at.UTC(), util.ZoneOffset(at), id)
+	if err != nil {
+		return 0, fmt.Errorf("error updating entry %d: %w", id, err)
+	}
+	if err := recordHistory(ctx, tx, int64(id), ActionEdit); err != nil {
+		return 0, err
+	}
+
+	newID, err := CreateEntry(ctx, tx, e.Name, e.Description.String, e.Project.String, at, e.EndTime, tags)
+	if err != nil {
+		return 0, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("error committing transaction: %w", err)
+	}
+	return newID, nil
+}
+
+// ValidateSplit checks that e can be split at at, which has to lie between
+// its start and end.
+func ValidateSplit(e Entry, at time.Time) error {
+	if !at.After(e.StartTime) || !at.Before(e.EndTime) {
+		return fmt.Errorf("split time must be between %s and %s", util.FormatTime(e.StartTime), util.FormatTime(e.EndTime))
+	}
+	return nil
+}
+
+// MergeEntries combines the entries with the given IDs into the first one,
+// which then spans all of them, lists their descriptions and has all their
+// tags. The other entries are deleted. Entries with other entries in
+// between cannot be merged, since the merged entry would cover them.
+func MergeEntries(ctx context.Context, db *sql.DB, ids ...int) error {
+	if len(ids) < 2 {
+		return fmt.Errorf("at least two entries are needed to merge")
+	}
+	for i, id := range ids {
+		if slices.Contains(ids[:i], id) {
+			return fmt.Errorf("entry %d is listed more than once", id)
+		}
+	}
+
+	tx, err := db.BeginTx(ctx, &sql.TxOptions{})
+	if err != nil {
+		return fmt.Errorf("error starting transaction: %w", err)
+	}
+
+	defer func() {
+		if rbErr := tx.Rollback(); rbErr != nil && rbErr != sql.ErrTxDone {
+			log.Printf("Error rolling back transaction: %v", rbErr)
+		}
+	}()
+
+	var merged Entry
+	var descriptions []string
+	for i, id := range ids {
+		e, err := scanEntry(tx.QueryRowContext(ctx, "SELECT "+entryColumns+" FROM entries WHERE id = ?", id))
+		if err == sql.ErrNoRows {
+			return fmt.Errorf("entry %d not found", id)
+		}
+		if err != nil {
+			return fmt.Errorf("error reading entry: %w", err)
+		}
+		e.StartTime = util.InOffset(e.StartTime, e.StartOffset)
+		e.EndTime = util.InOffset(e.EndTime, e.EndOffset)
+
+		if i == 0 {
+			merged = e
+		}
+		if e.StartTime.Before(merged.StartTime) {
+			merged.StartTime = e.StartTime
+		}
+		if e.EndTime.After(merged.EndTime) {
+			merged.EndTime = e.EndTime
+		}
+		if e.Description.String != "" && !slices.Contains(descriptions, e.Description.String) {
+			descriptions = append(descriptions, e.Description.String)
+		}
+	}
+
+	for _, id := range ids[1:] {
+		_, err := tx.ExecContext(ctx, "INSERT OR IGNORE INTO entry_tags (entry_id, tag_id) SELECT ?, tag_id FROM entry_tags WHERE entry_id = ?",
+			merged.ID, id)
+		if err != nil {
+			return fmt.Errorf("error moving tags of entry %d: %w", id, err)
+		}
+		if err := recordHistory(ctx, tx, int64(id), ActionDelete); err != nil {
+			return err
+		}
+		if _, err := tx.ExecContext(ctx, "DELETE FROM entry_tags WHERE entry_id = ?", id); err != nil {
+			return fmt.Errorf("error deleting tags of entry %d: %w", id, err)
+		}
+		if _, err := tx.ExecContext(ctx, "DELETE FROM entries WHERE id = ?", id); err != nil {
+			return fmt.Errorf("error deleting entry %d: %w", id, err)
+		}
+	}
+
+	// With the other entries gone, anything the merged entry overlaps lies
+	// outside of them and would be counted twice.
+	if err := findOverlap(ctx, tx, merged.StartTime, merged.EndTime, merged.ID); err != nil {
+		return fmt.Errorf("merged entry %w", err)
+	}
+
+	_, err = tx.ExecContext(ctx, "UPDATE entries SET description = ?, start_time = ?, end_time = ?, start_offset = ?, end_offset = ? WHERE id = ?",
+		nullString(strings.Join(descriptions, "; ")), merged.StartTime.UTC(), merged.EndTime.UTC(),
+		util.ZoneOffset(merged.StartTime), util.ZoneOffset(merged.EndTime), merged.ID)
+	if err != nil {
+		return fmt.Errorf("error updating entry %d: %w", merged.ID, err)
+	}
+
+	if err := recordHistory(ctx, tx, int64(merged.ID), ActionEdit); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("error committing transaction: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,165 @@
+// Package pomodoro runs cycles of focused work and breaks, recording every
+// work interval as a time entry.
+package pomodoro
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"go-time/pkgs/config"
+	"go-time/pkgs/countdown"
+	"go-time/pkgs/entry"
+)
+
+// Phase is a stage of a pomodoro cycle.
+type Phase int
+
+const (
+	Work Phase = iota
+	ShortBreak
+	LongBreak
+)
+
+func (p Phase) String() string {
+	switch p {
+	case ShortBreak:
+		return "Short break"
+	case LongBreak:
+		return "Long break"
+	}
+	return "Work"
+}
+
+// Settings are the lengths of the phases and the number of work intervals
+// after which a long break is taken instead of a short one.
+type Settings struct {
+	Work       time.Duration
+	ShortBreak time.Duration
+	LongBreak  time.Duration
+	Cycles     int
+}
+
+// Validate reports settings that cannot form a cycle.
+func (s Settings) Validate() error {
+	if s.Work <= 0 || s.ShortBreak <= 0 || s.LongBreak <= 0 {
+		return fmt.Errorf("work and break lengths must be positive")
+	}
+	if s.Cycles < 1 {
+		return fmt.Errorf("cycles must be at least 1, got %d", s.Cycles)
+	}
+	return nil
+}
+
+// Session is a running series of pomodoro cycles for a task.
+type Session struct {
+	Name     string
+	Tags     []string
+	Settings Settings
+
+	Phase Phase
+	// Completed is the number of work intervals finished so far.
+	Completed int
+	Countdown countdown.Model
+
+	// started is when the current phase began.
+	started time.Time
+}
+
+// Interval is a finished work interval and its number in the session.
+type Interval struct {
+	Start, End time.Time
+	Number     int
+}
+
+// NewSession starts a session with a work phase.
+func NewSession(name string, tags []string, settings Settings) Session {
+	s := Session{Name: name, Tags: tags, Settings: settings}
+	s.begin(Work)
+	return s
+}
+
+func (s *Session) begin(phase Phase) {
+	length := s.Settings.Work
+	switch phase {
+	case ShortBreak:
+		length = s.Settings.ShortBreak
+	case LongBreak:
+		length = s.Settings.LongBreak
+	}
+	s.Phase = phase
+	s.Countdown = countdown.New(length).Run()
+	s.started = time.Now()
+}
+
+// Advance moves on to the next phase if the current one is over, or
+// immediately if skip is true. When a work phase ends it returns the
+// interval that was worked so that it can be recorded.
+func (s *Session) Advance(skip bool) (Interval, bool) {
+	if !skip && !s.Countdown.Timedout() {
+		return Interval{}, false
+	}
+
+	if s.Phase != Work {
+		s.begin(Work)
+		return Interval{}, false
+	}
+
+	// A work phase that ran out ends when its time was up, even if that was
+	// noticed late.
+	end := time.Now()
+	if !skip {
+		end = s.started.Add(s.Settings.Work)
+	}
+	s.Completed++
+	worked := Interval{Start: s.started, End: end, Number: s.Completed}
+	if s.Completed%s.Settings.Cycles == 0 {
+		s.begin(LongBreak)
+	} else {
+		s.begin(ShortBreak)
+	}
+	return worked, true
+}
+
+// Stop ends the session. If it is stopped during a work phase, the time
+// worked so far is returned to be recorded.
+func (s *Session) Stop() (Interval, bool) {
+	if s.Phase != Work {
+		return Interval{}, false
+	}
+	return Interval{Start: s.started, End: time.Now(), Number: s.Completed + 1}, true
+}
+
+// Record saves a work interval of the session as an entry.
+func (s Session) Record(ctx context.Context, db *sql.DB, worked Interval) error {
+	if worked.End.Sub(worked.Start) < time.Second {
+		return nil
+	}
+	return entry.SaveForm(ctx, db, 0, entry.FormResult{
+		Name:        s.Name,
+		Description: fmt.Sprintf("Pomodoro %d", worked.Number),
+		StartTime:   worked.Start.Truncate(time.Second),
+		EndTime:     worked.End.Truncate(time.Second),
+		Tags:        s.Tags,
+	})
+}
+
+// Status describes the current phase, e.g. "Work 2/4".
+func (s Session) Status() string {
+	if s.Phase == Work {
+		return fmt.Sprintf("%s %d/%d", s.Phase, s.Completed%s.Settings.Cycles+1, s.Settings.Cycles)
+	}
+	return s.Phase.String()
+}
+
+// SettingsFrom returns the pomodoro settings of the app config, which are
+// given in minutes.
+func SettingsFrom(c config.AppConfig) Settings {
+	return Settings{
+		Work:       time.Duration(c.PomodoroWork) * time.Minute,
+		ShortBreak: time.Duration(c.PomodoroBreak) * time.Minute,
+		LongBreak:  time.Duration(c.PomodoroLongBreak) * time.Minute,
+		Cycles:     c.PomodoroCycles,
+	}
+}
@@ -0,0 +1,105 @@
+package pomodoro
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// Bell rings the terminal bell.
+const Bell = "\a"
+
+type tickMsg struct{}
+
+type recordedMsg struct {
+	worked Interval
+	err    error
+}
+
+// model shows a session in the terminal until it is stopped.
+type model struct {
+	db      *sql.DB
+	session Session
+	err     error
+}
+
+// Run shows the countdown of session in the terminal, moving through its
+// phases and recording work intervals until the user stops it.
+func Run(db *sql.DB, session Session) error {
+	m, err := tea.NewProgram(&model{db: db, session: session}).Run()
+	if err != nil {
+		return err
+	}
+	return m.(*model).err
+}
+
+func tick() tea.Cmd {
+	return tea.Tick(time.Second, func(time.Time) tea.Msg { return tickMsg{} })
+}
+
+// record returns a command that records worked and reports the result.
+func record(db *sql.DB, session Session, worked Interval) tea.Cmd {
+	return func() tea.Msg {
+		return recordedMsg{worked: worked, err: session.Record(context.Background(), db, worked)}
+	}
+}
+
+func (m *model) Init() tea.Cmd {
+	return tick()
+}
+
+func (m *model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		switch msg.String() {
+		case "q", "ctrl+c", "esc":
+			if worked, ok := m.session.Stop(); ok {
+				return m, tea.Sequence(record(m.db, m.session, worked), tea.Quit)
+			}
+			return m, tea.Quit
+		case "n":
+			return m, m.advance(true)
+		}
+
+	case tickMsg:
+		return m, tea.Batch(m.advance(false), tick())
+
+	case recordedMsg:
+		if msg.err != nil {
+			m.err = fmt.Errorf("error recording pomodoro: %w", msg.err)
+			return m, tea.Quit
+		}
+		return m, tea.Printf("Recorded pomodoro %d: %s - %s", msg.worked.Number,
+			msg.worked.Start.Format("15:04"), msg.worked.End.Format("15:04"))
+	}
+	return m, nil
+}
+
+// advance moves the session on when its phase is over, recording finished
+// work and ringing the bell.
+func (m *model) advance(skip bool) tea.Cmd {
+	phase := m.session.Phase
+	worked, ok := m.session.Advance(skip)
+	if m.session.Phase == phase && !ok {
+		return nil
+	}
+
+	cmds := []tea.Cmd{tea.Printf("%s finished, %s%s", phase, strings.ToLower(m.session.Phase.String()), Bell)}
+	if ok {
+		cmds = append(cmds, record(m.db, m.session, worked))
+	}
+	return tea.Sequence(cmds...)
+}
+
+func (m *model) View() string {
+	s := m.session
+	tags := ""
+	if len(s.Tags) > 0 {
+		tags = " [" + strings.Join(s.Tags, ", ") + "]"
+	}
+	return fmt.Sprintf("%s  %s  %s%s\n(n: next phase, q: stop)\n", s.Status(), s.Countdown.View(), s.Name, tags)
+}
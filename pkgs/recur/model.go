@@ -0,0 +1,329 @@
+package recur
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"go-time/pkgs/entry"
+)
+
+// querier is satisfied by both *sql.DB and *sql.Tx.
+type querier interface {
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+}
+
+// CreateRule saves a new rule.
+func CreateRule(ctx context.Context, db *sql.DB, r Rule) error {
+	if err := r.Validate(); err != nil {
+		return err
+	}
+
+	tx, err := db.BeginTx(ctx, &sql.TxOptions{})
+	if err != nil {
+		return fmt.Errorf("error starting transaction: %w", err)
+	}
+
+	defer func() {
+		if rbErr := tx.Rollback(); rbErr != nil && rbErr != sql.ErrTxDone {
+			log.Printf("transaction rollback error: %v", rbErr)
+		}
+	}()
+
+	var exists bool
+	if err := tx.QueryRowContext(ctx, "SELECT EXISTS (SELECT 1 FROM recurrences WHERE name = ?)", r.Name).Scan(&exists); err != nil {
+		return fmt.Errorf("error checking for rule: %w", err)
+	}
+	if exists {
+		return fmt.Errorf("rule %q already exists", r.Name)
+	}
+
+	var last sql.NullString
+	if !r.Last.IsZero() {
+		last = sql.NullString{String: r.Last.Format(DayLayout), Valid: true}
+	}
+	res, err := tx.ExecContext(ctx, `
+    INSERT INTO recurrences (name, description, frequency, interval, weekdays, start_clock, end_clock, first_day, last_day)
+    VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		r.Name, sql.NullString{String: r.Description, Valid: r.Description != ""}, string(r.Frequency), r.Interval,
+		FormatWeekdays(r.Weekdays), FormatClock(r.Start), FormatClock(r.End), r.First.Format(DayLayout), last)
+	if err != nil {
+		return fmt.Errorf("error saving rule: %w", err)
+	}
+	ruleID, err := res.LastInsertId()
+	if err != nil {
+		return fmt.Errorf("error getting last insert ID: %w", err)
+	}
+
+	for _, tag := range r.Tags {
+		if _, err := tx.ExecContext(ctx, "INSERT OR IGNORE INTO tags (name) VALUES (?)", tag); err != nil {
+			return fmt.Errorf("error inserting tag: %w", err)
+		}
+		_, err := tx.ExecContext(ctx, "INSERT OR IGNORE INTO recurrence_tags (recurrence_id, tag_id) SELECT ?, id FROM tags WHERE name = ?",
+			ruleID, tag)
+		if err != nil {
+			return fmt.Errorf("error linking tag with rule: %w", err)
+		}
+	}
+	for _, day := range r.Except {
+		_, err := tx.ExecContext(ctx, "INSERT OR IGNORE INTO recurrence_exceptions (recurrence_id, day) VALUES (?, ?)",
+			ruleID, day.Format(DayLayout))
+		if err != nil {
+			return fmt.Errorf("error saving exception: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("error committing transaction: %w", err)
+	}
+	return nil
+}
+
+// GetRules returns all rules ordered by name.
+func GetRules(ctx context.Context, db *sql.DB) ([]Rule, error) {
+	return fetchRules(ctx, db)
+}
+
+func fetchRules(ctx context.Context, tx querier) ([]Rule, error) {
+	rows, err := tx.QueryContext(ctx, `
+    SELECT id, name, description, frequency, interval, weekdays, start_clock, end_clock, first_day, last_day
+    FROM recurrences ORDER BY name`)
+	if err != nil {
+		return nil, fmt.Errorf("error querying rules: %w", err)
+	}
+	defer rows.Close()
+
+	var rules []Rule
+	for rows.Next() {
+		var r Rule
+		var description, last sql.NullString
+		var frequency, weekdays, start, end, first string
+		if err := rows.Scan(&r.ID, &r.Name, &description, &frequency, &r.Interval, &weekdays, &start, &end, &first, &last); err != nil {
+			return nil, fmt.Errorf("error scanning rule row: %w", err)
+		}
+		r.Description = description.String
+		r.Frequency = Frequency(frequency)
+		if r.Weekdays, err = ParseWeekdays(weekdays); err != nil {
+			return nil, fmt.Errorf("error reading rule %q: %w", r.Name, err)
+		}
+		if r.Start, err = ParseClock(start); err != nil {
+			return nil, fmt.Errorf("error reading rule %q: %w", r.Name, err)
+		}
+		if r.End, err = ParseClock(end); err != nil {
+			return nil, fmt.Errorf("error reading rule %q: %w", r.Name, err)
+		}
+		if r.First, err = ParseDay(first); err != nil {
+			return nil, fmt.Errorf("error reading rule %q: %w", r.Name, err)
+		}
+		if last.Valid {
+			if r.Last, err = ParseDay(last.String); err != nil {
+				return nil, fmt.Errorf("error reading rule %q: %w", r.Name, err)
+			}
+		}
+		rules = append(rules, r)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating over rule rows: %w", err)
+	}
+	rows.Close()
+
+	for i := range rules {
+		if rules[i].Tags, err = fetchStrings(ctx, tx, "SELECT t.name FROM tags t INNER JOIN recurrence_tags rt ON t.id = rt.tag_id "+
+			"WHERE rt.recurrence_id = ? ORDER BY t.name", rules[i].ID); err != nil {
+			return nil, fmt.Errorf("error fetching tags for rule: %w", err)
+		}
+		days, err := fetchStrings(ctx, tx, "SELECT day FROM recurrence_exceptions WHERE recurrence_id = ? ORDER BY day", rules[i].ID)
+		if err != nil {
+			return nil, fmt.Errorf("error fetching exceptions for rule: %w", err)
+		}
+		for _, s := range days {
+			day, err := ParseDay(s)
+			if err != nil {
+				return nil, fmt.Errorf("error reading rule %q: %w", rules[i].Name, err)
+			}
+			rules[i].Except = append(rules[i].Except, day)
+		}
+	}
+	return rules, nil
+}
+
+func fetchStrings(ctx context.Context, tx querier, query string, args ...any) ([]string, error) {
+	rows, err := tx.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var values []string
+	for rows.Next() {
+		var s string
+		if err := rows.Scan(&s); err != nil {
+			return nil, err
+		}
+		values = append(values, s)
+	}
+	return values, rows.Err()
+}
+
+// DeleteRule deletes the rule called name. Entries it created are kept.
+func DeleteRule(ctx context.Context, db *sql.DB, name string) error {
+	tx, err := db.BeginTx(ctx, &sql.TxOptions{})
+	if err != nil {
+		return fmt.Errorf("error starting transaction: %w", err)
+	}
+
+	defer func() {
+		if rbErr := tx.Rollback(); rbErr != nil && rbErr != sql.ErrTxDone {
+			log.Printf("transaction rollback error: %v", rbErr)
+		}
+	}()
+
+	id, err := ruleID(ctx, tx, name)
+	if err != nil {
+		return err
+	}
+	for _, table := range []string{"recurrence_tags", "recurrence_exceptions", "recurrence_entries"} {
+		if _, err := tx.ExecContext(ctx, "DELETE FROM "+table+" WHERE recurrence_id = ?", id); err != nil {
+			return fmt.Errorf("error deleting from %s: %w", table, err)
+		}
+	}
+	if _, err := tx.ExecContext(ctx, "DELETE FROM recurrences WHERE id = ?", id); err != nil {
+		return fmt.Errorf("error deleting rule: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("error committing transaction: %w", err)
+	}
+	return nil
+}
+
+// AddException skips the rule called name on day.
+func AddException(ctx context.Context, db *sql.DB, name string, day time.Time) error {
+	tx, err := db.BeginTx(ctx, &sql.TxOptions{})
+	if err != nil {
+		return fmt.Errorf("error starting transaction: %w", err)
+	}
+
+	defer func() {
+		if rbErr := tx.Rollback(); rbErr != nil && rbErr != sql.ErrTxDone {
+			log.Printf("transaction rollback error: %v", rbErr)
+		}
+	}()
+
+	id, err := ruleID(ctx, tx, name)
+	if err != nil {
+		return err
+	}
+	_, err = tx.ExecContext(ctx, "INSERT OR IGNORE INTO recurrence_exceptions (recurrence_id, day) VALUES (?, ?)", id, day.Format(DayLayout))
+	if err != nil {
+		return fmt.Errorf("error saving exception: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("error committing transaction: %w", err)
+	}
+	return nil
+}
+
+func ruleID(ctx context.Context, tx *sql.Tx, name string) (int, error) {
+	var id int
+	err := tx.QueryRowContext(ctx, "SELECT id FROM recurrences WHERE name = ?", name).Scan(&id)
+	if err == sql.ErrNoRows {
+		return 0, fmt.Errorf("no rule named %q", name)
+	}
+	if err != nil {
+		return 0, fmt.Errorf("error fetching rule: %w", err)
+	}
+	return id, nil
+}
+
+// Applied is an occurrence handled by Apply.
+type Applied struct {
+	Rule string
+	Occurrence
+	// EntryID is the entry that tracks the occurrence. Existing is true if
+	// the entry was already there, tracked by hand under the name of the
+	// rule. Err is set instead if no entry could be created.
+	EntryID  int
+	Existing bool
+	Err      error
+}
+
+// Apply creates entries for the occurrences of every rule up to and
+// including the day until that have ended by now. Each occurrence is
+// created once: occurrences that Apply handled before, including those
+// whose entries were deleted since, are left alone, and an entry with the
+// name of the rule overlapping an occurrence counts as tracking it. An
+// occurrence that cannot be created because it overlaps another entry in
+// strict mode is reported and tried again on the next run.
+func Apply(ctx context.Context, db *sql.DB, until, now time.Time) ([]Applied, error) {
+	tx, err := db.BeginTx(ctx, &sql.TxOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("error starting transaction: %w", err)
+	}
+
+	defer func() {
+		if rbErr := tx.Rollback(); rbErr != nil && rbErr != sql.ErrTxDone {
+			log.Printf("transaction rollback error: %v", rbErr)
+		}
+	}()
+
+	rules, err := fetchRules(ctx, tx)
+	if err != nil {
+		return nil, err
+	}
+
+	var applied []Applied
+	for _, r := range rules {
+		done, err := fetchStrings(ctx, tx, "SELECT day FROM recurrence_entries WHERE recurrence_id = ?", r.ID)
+		if err != nil {
+			return nil, fmt.Errorf("error fetching applied occurrences: %w", err)
+		}
+		handled := make(map[string]bool, len(done))
+		for _, day := range done {
+			handled[day] = true
+		}
+
+		for _, o := range r.Occurrences(r.First, until) {
+			if handled[o.Day.Format(DayLayout)] || o.End.After(now) {
+				continue
+			}
+			a := Applied{Rule: r.Name, Occurrence: o}
+
+			err := tx.QueryRowContext(ctx, "SELECT id FROM entries WHERE name = ? AND end_time > ? AND start_time < ? ORDER BY start_time LIMIT 1",
+				r.Name, o.Start.UTC(), o.End.UTC()).Scan(&a.EntryID)
+			switch {
+			case err == nil:
+				a.Existing = true
+			case err != sql.ErrNoRows:
+				return nil, fmt.Errorf("error checking for existing entries: %w", err)
+			default:
+				a.EntryID, err = entry.CreateEntry(ctx, tx, r.Name, r.Description, "", o.Start, o.End, r.Tags)
+				var overlap entry.OverlapError
+				if errors.As(err, &overlap) {
+					a.Err = err
+					applied = append(applied, a)
+					continue
+				}
+				if err != nil {
+					return nil, fmt.Errorf("error creating entry for %s on %s: %w", r.Name, o.Day.Format(DayLayout), err)
+				}
+			}
+
+			_, err = tx.ExecContext(ctx, "INSERT INTO recurrence_entries (recurrence_id, day, entry_id) VALUES (?, ?, ?)",
+				r.ID, o.Day.Format(DayLayout), a.EntryID)
+			if err != nil {
+				return nil, fmt.Errorf("error recording occurrence: %w", err)
+			}
+			applied = append(applied, a)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("error committing transaction: %w", err)
+	}
+	return applied, nil
+}
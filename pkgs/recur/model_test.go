@@ -0,0 +1,173 @@
+package recur
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"go-time/db"
+	"go-time/pkgs/entry"
+)
+
+func newTestDB(t *testing.T) *sql.DB {
+	t.Helper()
+	useLocation(t, "UTC")
+	database, err := db.InitDB(filepath.Join(t.TempDir(), "go-time.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { database.Close() })
+	return database
+}
+
+// createStandup saves a daily rule from 09:30 to 09:45 starting on 19
+// October 2026.
+func createStandup(t *testing.T, database *sql.DB) {
+	t.Helper()
+	r := Rule{Name: "standup", Tags: []string{"meeting"}, Frequency: Daily, Interval: 1,
+		Start: 9*time.Hour + 30*time.Minute, End: 9*time.Hour + 45*time.Minute, First: mustDay(t, "2026-10-19")}
+	if err := CreateRule(context.Background(), database, r); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func createEntry(t *testing.T, database *sql.DB, name string, start, end time.Time) int {
+	t.Helper()
+	ctx := context.Background()
+	tx, err := database.BeginTx(ctx, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tx.Rollback()
+	id, err := entry.CreateEntry(ctx, tx, name, "", "", start, end, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := tx.Commit(); err != nil {
+		t.Fatal(err)
+	}
+	return id
+}
+
+func apply(t *testing.T, database *sql.DB, until string, now time.Time) []Applied {
+	t.Helper()
+	applied, err := Apply(context.Background(), database, mustDay(t, until), now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return applied
+}
+
+func countEntries(t *testing.T, database *sql.DB) int {
+	t.Helper()
+	var n int
+	if err := database.QueryRow("SELECT COUNT(*) FROM entries").Scan(&n); err != nil {
+		t.Fatal(err)
+	}
+	return n
+}
+
+func appliedDays(applied []Applied) []string {
+	days := make([]string, len(applied))
+	for i, a := range applied {
+		days[i] = a.Day.Format(DayLayout)
+	}
+	return days
+}
+
+func TestApply(t *testing.T) {
+	database := newTestDB(t)
+	createStandup(t, database)
+
+	// The occurrence of the 21st is still running.
+	now := time.Date(2026, 10, 21, 9, 40, 0, 0, time.UTC)
+	applied := apply(t, database, "2026-10-21", now)
+	if got := appliedDays(applied); len(got) != 2 || got[0] != "2026-10-19" || got[1] != "2026-10-20" {
+		t.Fatalf("Apply() handled %v, want 2026-10-19 and 2026-10-20", got)
+	}
+	for _, a := range applied {
+		if a.Err != nil || a.Existing || a.EntryID == 0 {
+			t.Errorf("Apply() = %+v, want a new entry", a)
+		}
+	}
+
+	e, err := entry.GetEntry(context.Background(), database, applied[0].EntryID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := time.Date(2026, 10, 19, 9, 30, 0, 0, time.UTC); e.Name != "standup" || !e.StartTime.Equal(want) ||
+		e.Duration() != 15*time.Minute || len(e.Tags) != 1 || e.Tags[0].Name != "meeting" {
+		t.Errorf("created entry %+v, want standup tagged meeting from %v for 15m", e, want)
+	}
+
+	// Running again creates no duplicates, even for occurrences whose
+	// entries were deleted since.
+	if err := entry.DeleteEntry(context.Background(), database, applied[0].EntryID); err != nil {
+		t.Fatal(err)
+	}
+	if applied := apply(t, database, "2026-10-21", now); len(applied) != 0 {
+		t.Errorf("second Apply() handled %v, want nothing", appliedDays(applied))
+	}
+	if n := countEntries(t, database); n != 1 {
+		t.Errorf("%d entries after the second run, want 1", n)
+	}
+
+	applied = apply(t, database, "2026-10-21", now.Add(5*time.Minute))
+	if got := appliedDays(applied); len(got) != 1 || got[0] != "2026-10-21" {
+		t.Errorf("Apply() after the occurrence ended handled %v, want 2026-10-21", got)
+	}
+}
+
+func TestApplyExistingEntry(t *testing.T) {
+	database := newTestDB(t)
+	createStandup(t, database)
+
+	// The standup of the 19th was tracked by hand and ran late.
+	id := createEntry(t, database, "standup",
+		time.Date(2026, 10, 19, 9, 35, 0, 0, time.UTC), time.Date(2026, 10, 19, 9, 55, 0, 0, time.UTC))
+
+	applied := apply(t, database, "2026-10-19", time.Date(2026, 10, 20, 0, 0, 0, 0, time.UTC))
+	if len(applied) != 1 || !applied[0].Existing || applied[0].EntryID != id {
+		t.Fatalf("Apply() = %+v, want the existing entry %d", applied, id)
+	}
+	if n := countEntries(t, database); n != 1 {
+		t.Errorf("%d entries after Apply, want 1", n)
+	}
+}
+
+func TestApplyRetriesOverlap(t *testing.T) {
+	database := newTestDB(t)
+	createStandup(t, database)
+	entry.SetStrict(true)
+	t.Cleanup(func() { entry.SetStrict(false) })
+
+	createEntry(t, database, "call", time.Date(2026, 10, 19, 9, 0, 0, 0, time.UTC), time.Date(2026, 10, 19, 10, 0, 0, 0, time.UTC))
+	now := time.Date(2026, 10, 21, 0, 0, 0, 0, time.UTC)
+
+	applied := apply(t, database, "2026-10-20", now)
+	var overlap entry.OverlapError
+	if len(applied) != 2 || !errors.As(applied[0].Err, &overlap) || applied[1].Err != nil {
+		t.Fatalf("Apply() = %+v, want an overlap on 2026-10-19 and a new entry on 2026-10-20", applied)
+	}
+
+	// The overlapping occurrence is tried again on every run.
+	applied = apply(t, database, "2026-10-20", now)
+	if len(applied) != 1 || !errors.As(applied[0].Err, &overlap) {
+		t.Fatalf("second Apply() = %+v, want the overlap on 2026-10-19 again", applied)
+	}
+
+	entry.SetStrict(false)
+	applied = apply(t, database, "2026-10-20", now)
+	if got := appliedDays(applied); len(got) != 1 || got[0] != "2026-10-19" || applied[0].Err != nil {
+		t.Fatalf("Apply() without strict mode = %+v, want a new entry on 2026-10-19", applied)
+	}
+	if applied := apply(t, database, "2026-10-20", now); len(applied) != 0 {
+		t.Errorf("last Apply() handled %v, want nothing", appliedDays(applied))
+	}
+	if n := countEntries(t, database); n != 3 {
+		t.Errorf("%d entries, want the call and two standups", n)
+	}
+}
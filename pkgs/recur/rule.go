@@ -0,0 +1,234 @@
+package recur
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"go-time/pkgs/util"
+)
+
+// DayLayout is the format of the days rules start, end and skip on. Days
+// are calendar days in the configured zone rather than instants, so they
+// are stored in this format.
+const DayLayout = "2006-01-02"
+
+// clockLayout is the format of the times of day occurrences start and end.
+const clockLayout = "15:04"
+
+// Frequency is how often a rule repeats.
+type Frequency string
+
+const (
+	Daily  Frequency = "daily"
+	Weekly Frequency = "weekly"
+)
+
+// ParseFrequency parses "daily" or "weekly".
+func ParseFrequency(s string) (Frequency, error) {
+	switch f := Frequency(strings.ToLower(s)); f {
+	case Daily, Weekly:
+		return f, nil
+	}
+	return "", fmt.Errorf("unknown frequency %q, expected daily or weekly", s)
+}
+
+// Rule describes an entry that recurs at the same time of day, like a
+// daily standup from 09:30 to 09:45.
+type Rule struct {
+	ID          int
+	Name        string
+	Description string
+	Tags        []string
+
+	// Frequency and Interval set how often the rule repeats: every
+	// Interval days or weeks. Weekly rules repeat on Weekdays, or on the
+	// weekday of First if there are none.
+	Frequency Frequency
+	Interval  int
+	Weekdays  []time.Weekday
+
+	// Start and End are the times of day of each occurrence, as durations
+	// since midnight.
+	Start, End time.Duration
+
+	// First and Last are the first and last days the rule applies to, at
+	// midnight. A zero Last means the rule does not end. Except lists the
+	// days it is skipped on.
+	First, Last time.Time
+	Except      []time.Time
+}
+
+// Validate checks that the rule can produce occurrences.
+func (r Rule) Validate() error {
+	if strings.TrimSpace(r.Name) == "" {
+		return fmt.Errorf("name cannot be empty")
+	}
+	if r.Frequency != Daily && r.Frequency != Weekly {
+		return fmt.Errorf("unknown frequency %q, expected daily or weekly", r.Frequency)
+	}
+	if r.Interval < 1 {
+		return fmt.Errorf("interval must be at least 1")
+	}
+	if r.Start < 0 || r.End > 24*time.Hour || r.End <= r.Start {
+		return fmt.Errorf("end time must be after start time on the same day")
+	}
+	if r.First.IsZero() {
+		return fmt.Errorf("first day is required")
+	}
+	if !r.Last.IsZero() && r.Last.Before(r.First) {
+		return fmt.Errorf("last day cannot be before the first day")
+	}
+	return nil
+}
+
+// Occurs reports whether the rule has an occurrence on day.
+func (r Rule) Occurs(day time.Time) bool {
+	if day.Before(r.First) || (!r.Last.IsZero() && day.After(r.Last)) {
+		return false
+	}
+	for _, except := range r.Except {
+		if sameDay(except, day) {
+			return false
+		}
+	}
+
+	switch r.Frequency {
+	case Daily:
+		return daysBetween(r.First, day)%r.Interval == 0
+	case Weekly:
+		weekdays := r.Weekdays
+		if len(weekdays) == 0 {
+			weekdays = []time.Weekday{r.First.Weekday()}
+		}
+		if !slices.Contains(weekdays, day.Weekday()) {
+			return false
+		}
+		return daysBetween(weekStart(r.First), weekStart(day))/7%r.Interval == 0
+	}
+	return false
+}
+
+// Occurrence is a single time a rule recurs.
+type Occurrence struct {
+	Day        time.Time
+	Start, End time.Time
+}
+
+// Occurrences returns the occurrences of the rule on the days from from to
+// to, inclusive.
+func (r Rule) Occurrences(from, to time.Time) []Occurrence {
+	var occurrences []Occurrence
+	if from.Before(r.First) {
+		from = r.First
+	}
+	for day := util.StartOfDay(from); !day.After(to); day = nextDay(day) {
+		if r.Occurs(day) {
+			occurrences = append(occurrences, Occurrence{Day: day, Start: atTime(day, r.Start), End: atTime(day, r.End)})
+		}
+	}
+	return occurrences
+}
+
+// Schedule describes when the rule recurs, e.g. "weekly on mon, wed
+// 09:30-09:45".
+func (r Rule) Schedule() string {
+	var s string
+	switch {
+	case r.Interval > 1 && r.Frequency == Daily:
+		s = fmt.Sprintf("every %d days", r.Interval)
+	case r.Interval > 1:
+		s = fmt.Sprintf("every %d weeks", r.Interval)
+	default:
+		s = string(r.Frequency)
+	}
+	if r.Frequency == Weekly && len(r.Weekdays) > 0 {
+		s += " on " + FormatWeekdays(r.Weekdays)
+	}
+	return s + " " + FormatClock(r.Start) + "-" + FormatClock(r.End)
+}
+
+var weekdayNames = []string{"sun", "mon", "tue", "wed", "thu", "fri", "sat"}
+
+// ParseWeekdays parses a comma separated list of weekdays such as
+// "mon,wed,fri".
+func ParseWeekdays(s string) ([]time.Weekday, error) {
+	var weekdays []time.Weekday
+	for _, name := range strings.Split(s, ",") {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" {
+			continue
+		}
+		i := slices.Index(weekdayNames, name)
+		if i < 0 {
+			return nil, fmt.Errorf("unknown weekday %q, expected mon, tue, wed, thu, fri, sat or sun", name)
+		}
+		if !slices.Contains(weekdays, time.Weekday(i)) {
+			weekdays = append(weekdays, time.Weekday(i))
+		}
+	}
+	slices.Sort(weekdays)
+	return weekdays, nil
+}
+
+// FormatWeekdays formats weekdays as ParseWeekdays reads them.
+func FormatWeekdays(weekdays []time.Weekday) string {
+	names := make([]string, len(weekdays))
+	for i, d := range weekdays {
+		names[i] = weekdayNames[d]
+	}
+	return strings.Join(names, ",")
+}
+
+// ParseClock parses a time of day such as "09:30" into the duration since
+// midnight. "24:00" is the end of the day.
+func ParseClock(s string) (time.Duration, error) {
+	if s == "24:00" {
+		return 24 * time.Hour, nil
+	}
+	t, err := time.Parse(clockLayout, s)
+	if err != nil {
+		return 0, fmt.Errorf("invalid time of day %q, expected HH:MM", s)
+	}
+	return time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute, nil
+}
+
+// FormatClock formats a duration since midnight as a time of day.
+func FormatClock(d time.Duration) string {
+	return fmt.Sprintf("%02d:%02d", d/time.Hour, (d%time.Hour)/time.Minute)
+}
+
+// ParseDay parses a day in DayLayout in the configured zone.
+func ParseDay(s string) (time.Time, error) {
+	day, err := time.ParseInLocation(DayLayout, s, util.Location())
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid day %q, expected YYYY-MM-DD", s)
+	}
+	return day, nil
+}
+
+// atTime returns the time d after midnight on day, as shown on a clock.
+func atTime(day time.Time, d time.Duration) time.Time {
+	return time.Date(day.Year(), day.Month(), day.Day(), 0, int(d/time.Minute), 0, 0, day.Location())
+}
+
+func nextDay(day time.Time) time.Time {
+	return time.Date(day.Year(), day.Month(), day.Day()+1, 0, 0, 0, 0, day.Location())
+}
+
+func weekStart(day time.Time) time.Time {
+	return day.AddDate(0, 0, -(int(day.Weekday())+6)%7)
+}
+
+func sameDay(a, b time.Time) bool {
+	return a.Year() == b.Year() && a.YearDay() == b.YearDay()
+}
+
+// daysBetween counts the calendar days from a to b, regardless of changes
+// to daylight saving time in between.
+func daysBetween(a, b time.Time) int {
+	ad := time.Date(a.Year(), a.Month(), a.Day(), 0, 0, 0, 0, time.UTC)
+	bd := time.Date(b.Year(), b.Month(), b.Day(), 0, 0, 0, 0, time.UTC)
+	return int(bd.Sub(ad).Hours() / 24)
+}
@@ -0,0 +1,136 @@
+package recur
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"go-time/pkgs/util"
+)
+
+// useLocation sets the configured zone for the duration of the test.
+func useLocation(t *testing.T, name string) {
+	t.Helper()
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	previous := util.Location()
+	util.SetLocation(loc)
+	t.Cleanup(func() { util.SetLocation(previous) })
+}
+
+func mustDay(t *testing.T, s string) time.Time {
+	t.Helper()
+	day, err := ParseDay(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return day
+}
+
+func occurrenceDays(occurrences []Occurrence) []string {
+	days := make([]string, len(occurrences))
+	for i, o := range occurrences {
+		days[i] = o.Day.Format(DayLayout)
+	}
+	return days
+}
+
+func TestOccurrences(t *testing.T) {
+	useLocation(t, "UTC")
+
+	tests := []struct {
+		name     string
+		rule     Rule
+		from, to string
+		want     []string
+	}{
+		{
+			name: "daily",
+			rule: Rule{Frequency: Daily, Interval: 1, First: mustDay(t, "2026-10-19")},
+			from: "2026-10-19", to: "2026-10-21",
+			want: []string{"2026-10-19", "2026-10-20", "2026-10-21"},
+		},
+		{
+			name: "every third day",
+			rule: Rule{Frequency: Daily, Interval: 3, First: mustDay(t, "2026-10-19")},
+			from: "2026-10-20", to: "2026-10-28",
+			want: []string{"2026-10-22", "2026-10-25", "2026-10-28"},
+		},
+		{
+			name: "first and last day and exceptions",
+			rule: Rule{Frequency: Daily, Interval: 1, First: mustDay(t, "2026-10-19"), Last: mustDay(t, "2026-10-23"),
+				Except: []time.Time{mustDay(t, "2026-10-21")}},
+			from: "2026-10-01", to: "2026-10-31",
+			want: []string{"2026-10-19", "2026-10-20", "2026-10-22", "2026-10-23"},
+		},
+		{
+			name: "weekly on the weekday of the first day",
+			rule: Rule{Frequency: Weekly, Interval: 1, First: mustDay(t, "2026-10-21")},
+			from: "2026-10-19", to: "2026-11-05",
+			want: []string{"2026-10-21", "2026-10-28", "2026-11-04"},
+		},
+		{
+			name: "every other week on weekdays",
+			rule: Rule{Frequency: Weekly, Interval: 2, Weekdays: []time.Weekday{time.Monday, time.Friday},
+				First: mustDay(t, "2026-10-21")},
+			from: "2026-10-19", to: "2026-11-08",
+			want: []string{"2026-10-23", "2026-11-02", "2026-11-06"},
+		},
+		{
+			// Weeks start on Monday, so the Sunday of the first day belongs
+			// to the week before the following Monday.
+			name: "every other week from a sunday",
+			rule: Rule{Frequency: Weekly, Interval: 2, Weekdays: []time.Weekday{time.Sunday, time.Monday},
+				First: mustDay(t, "2026-10-25")},
+			from: "2026-10-19", to: "2026-11-09",
+			want: []string{"2026-10-25", "2026-11-02", "2026-11-08"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := occurrenceDays(tt.rule.Occurrences(mustDay(t, tt.from), mustDay(t, tt.to)))
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("Occurrences() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestOccurrencesAcrossDST(t *testing.T) {
+	useLocation(t, "Europe/Berlin")
+
+	// Daylight saving time ends on 25 October 2026 in Berlin.
+	daily := Rule{Frequency: Daily, Interval: 1, Start: 9 * time.Hour, End: 10 * time.Hour, First: mustDay(t, "2026-10-24")}
+	occurrences := daily.Occurrences(mustDay(t, "2026-10-24"), mustDay(t, "2026-10-26"))
+	wantUTC := []string{"07:00", "08:00", "08:00"}
+	if len(occurrences) != len(wantUTC) {
+		t.Fatalf("Occurrences() returned %v, want 3 days", occurrenceDays(occurrences))
+	}
+	for i, o := range occurrences {
+		if got := o.Start.Format("15:04"); got != "09:00" {
+			t.Errorf("occurrence %d starts at %s local time, want 09:00", i, got)
+		}
+		if got := o.Start.UTC().Format("15:04"); got != wantUTC[i] {
+			t.Errorf("occurrence %d starts at %s UTC, want %s", i, got, wantUTC[i])
+		}
+		if o.End.Sub(o.Start) != time.Hour {
+			t.Errorf("occurrence %d lasts %v, want 1h0m0s", i, o.End.Sub(o.Start))
+		}
+	}
+
+	// Weeks that are an hour shorter or longer still count as weeks.
+	weekly := Rule{Frequency: Weekly, Interval: 2, Start: 9 * time.Hour, End: 10 * time.Hour, First: mustDay(t, "2026-03-23")}
+	got := occurrenceDays(weekly.Occurrences(mustDay(t, "2026-03-01"), mustDay(t, "2026-04-30")))
+	want := []string{"2026-03-23", "2026-04-06", "2026-04-20"}
+	if !slices.Equal(got, want) {
+		t.Errorf("Occurrences() = %v, want %v", got, want)
+	}
+	got = occurrenceDays(weekly.Occurrences(mustDay(t, "2026-10-01"), mustDay(t, "2026-11-10")))
+	want = []string{"2026-10-05", "2026-10-19", "2026-11-02"}
+	if !slices.Equal(got, want) {
+		t.Errorf("Occurrences() = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,92 @@
+package report
+
+import (
+	"sort"
+	"time"
+
+	"go-time/pkgs/entry"
+	"go-time/pkgs/util"
+)
+
+// DayTotal is the time tracked on a single calendar day.
+type DayTotal struct {
+	Day      time.Time
+	Duration time.Duration
+}
+
+// DailyTotals sums the tracked time of entries for every day from the day
+// containing from up to and including the day containing to. Days are
+// calendar days in the configured zone, so entries that cross midnight are
+// split between the days they span and DST transitions produce 23 or 25 hour
+// days.
+func DailyTotals(entries []entry.Entry, from, to time.Time) []DayTotal {
+	var totals []DayTotal
+	for day := util.StartOfDay(from); !day.After(to); day = nextDay(day) {
+		end := nextDay(day)
+		total := DayTotal{Day: day}
+		for _, e := range entries {
+			total.Duration += overlap(e.StartTime, e.EndTime, day, end)
+		}
+		totals = append(totals, total)
+	}
+	return totals
+}
+
+// Total returns the sum of all daily totals.
+func Total(totals []DayTotal) time.Duration {
+	var sum time.Duration
+	for _, t := range totals {
+		sum += t.Duration
+	}
+	return sum
+}
+
+// TagTotal is the time tracked with a single tag.
+type TagTotal struct {
+	Tag      string
+	Duration time.Duration
+}
+
+// TagTotals sums the tracked time of entries between from and to per tag,
+// largest first. An entry with several tags counts towards each of them.
+func TagTotals(entries []entry.Entry, from, to time.Time) []TagTotal {
+	sums := make(map[string]time.Duration)
+	for _, e := range entries {
+		d := overlap(e.StartTime, e.EndTime, from, to)
+		if d == 0 {
+			continue
+		}
+		for _, t := range e.Tags {
+			sums[t.Name] += d
+		}
+	}
+
+	totals := make([]TagTotal, 0, len(sums))
+	for name, d := range sums {
+		totals = append(totals, TagTotal{Tag: name, Duration: d})
+	}
+	sort.Slice(totals, func(i, j int) bool {
+		if totals[i].Duration != totals[j].Duration {
+			return totals[i].Duration > totals[j].Duration
+		}
+		return totals[i].Tag < totals[j].Tag
+	})
+	return totals
+}
+
+func nextDay(day time.Time) time.Time {
+	return time.Date(day.Year(), day.Month(), day.Day()+1, 0, 0, 0, 0, day.Location())
+}
+
+func overlap(start, end, rangeStart, rangeEnd time.Time) time.Duration {
+	if start.Before(rangeStart) {
+		start = rangeStart
+	}
+	if end.After(rangeEnd) {
+		end = rangeEnd
+	}
+	if !end.After(start) {
+		return 0
+	}
+	return end.Sub(start)
+}
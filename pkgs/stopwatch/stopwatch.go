@@ -4,6 +4,7 @@ package stopwatch
 import (
 	"fmt"
 	tea "github.com/charmbracelet/bubbletea"
+	"slices"
 	"sync"
 	"time"
 )
@@ -32,6 +33,11 @@ type TickMsg struct {
 	ID int
 }
 
+// SharedTickMsg redraws every stopwatch. Unlike TickMsg it does not
+// schedule another tick, so a single Tick command can drive any number of
+// stopwatches.
+type SharedTickMsg struct{}
+
 // StartStopMsg is sent when the stopwatch should start or stop.
 type StartStopMsg struct {
 	ID      int
@@ -43,22 +49,39 @@ type ResetMsg struct {
 	ID int
 }
 
-// Model for the stopwatch component.
+// Lap is a point marked on the stopwatch, with an optional note.
+type Lap struct {
+	Elapsed time.Duration
+	Note    string
+}
+
+// Model for the stopwatch component. The elapsed time is measured against
+// the clock rather than counted in ticks, so it stays correct when ticks are
+// delayed or missed, e.g. while the machine is suspended. Ticks only cause
+// the stopwatch to be redrawn.
 type Model struct {
+	// d is the time accumulated before the stopwatch was last started, and
+	// since the time it was started at while it is running.
 	d       time.Duration
+	since   time.Time
 	id      int
 	running bool
+	laps    []Lap
 
 	// How long to wait before every tick. Defaults to 1 second.
 	Interval time.Duration
+
+	// Clock returns the current time. Defaults to time.Now.
+	Clock func() time.Time
 }
 
-// NewWithInterval creates a new stopwatch with the given timeout and tick
-// interval.
+// NewWithInterval creates a new stopwatch with the given elapsed time and
+// tick interval.
 func NewWithInterval(d time.Duration, interval time.Duration) Model {
 	return Model{
 		d:        d,
 		Interval: interval,
+		Clock:    time.Now,
 		id:       nextID(),
 	}
 }
@@ -85,6 +108,12 @@ func (m Model) Start() tea.Cmd {
 	}, tick(m.id, m.Interval))
 }
 
+// Run returns the stopwatch started without a tick of its own, to be driven
+// by SharedTickMsg.
+func (m Model) Run() Model {
+	return m.setRunning(true)
+}
+
 // Stop stops the stopwatch.
 func (m Model) Stop() tea.Cmd {
 	return func() tea.Msg {
@@ -100,7 +129,7 @@ func (m Model) Toggle() tea.Cmd {
 	return m.Start()
 }
 
-// Reset resets the stopwatch to 0.
+// Reset resets the stopwatch to 0 and clears its laps.
 func (m Model) Reset() tea.Cmd {
 	return func() tea.Msg {
 		return ResetMsg{ID: m.id}
@@ -119,43 +148,97 @@ func (m Model) Update(msg tea.Msg) (Model, tea.Cmd) {
 		if msg.ID != m.id {
 			return m, nil
 		}
-		m.running = msg.running
+		m = m.setRunning(msg.running)
 	case ResetMsg:
 		if msg.ID != m.id {
 			return m, nil
 		}
-		m.d = 0
+		m = m.SetElapsedTime(0)
+		m.laps = nil
 	case TickMsg:
 		if !m.running || msg.ID != m.id {
 			break
 		}
-		m.d += m.Interval
 		return m, tick(m.id, m.Interval)
 	}
 
 	return m, nil
 }
 
+// setRunning starts or stops the stopwatch, moving the time elapsed while it
+// ran into d when it stops.
+func (m Model) setRunning(running bool) Model {
+	if running == m.running {
+		return m
+	}
+	if running {
+		m.since = m.now()
+	} else {
+		m.d = m.Elapsed()
+	}
+	m.running = running
+	return m
+}
+
+func (m Model) now() time.Time {
+	if m.Clock == nil {
+		return time.Now()
+	}
+	return m.Clock()
+}
+
 // Elapsed returns the time elapsed.
 func (m Model) Elapsed() time.Duration {
-	return m.d
+	if !m.running {
+		return m.d
+	}
+	return m.d + m.now().Sub(m.since)
 }
 
 // View of the timer component.
 func (m Model) View() string {
-	hours := m.d / time.Hour
-	minutes := (m.d % time.Hour) / time.Minute
-	seconds := (m.d % time.Minute) / time.Second
+	d := m.Elapsed()
+	hours := d / time.Hour
+	minutes := (d % time.Hour) / time.Minute
+	seconds := (d % time.Minute) / time.Second
 	return fmt.Sprintf("%02dh%02dm%02ds", hours, minutes, seconds)
 }
 
+// Tick returns a command that sends a SharedTickMsg after d.
+func Tick(d time.Duration) tea.Cmd {
+	return tea.Tick(d, func(_ time.Time) tea.Msg {
+		return SharedTickMsg{}
+	})
+}
+
 func tick(id int, d time.Duration) tea.Cmd {
 	return tea.Tick(d, func(_ time.Time) tea.Msg {
 		return TickMsg{ID: id}
 	})
 }
 
+// SetElapsedTime sets the time elapsed so far. A running stopwatch keeps
+// counting from there.
 func (m Model) SetElapsedTime(d time.Duration) Model {
 	m.d = d
+	m.since = m.now()
 	return m
 }
+
+// Lap marks a lap with note at the time elapsed so far.
+func (m Model) Lap(note string) Model {
+	m.laps = append(slices.Clip(m.laps), Lap{Elapsed: m.Elapsed(), Note: note})
+	return m
+}
+
+// SetLaps replaces the laps marked so far, e.g. with laps that were stored
+// while the stopwatch was not shown.
+func (m Model) SetLaps(laps []Lap) Model {
+	m.laps = laps
+	return m
+}
+
+// Laps returns the laps in the order they were marked.
+func (m Model) Laps() []Lap {
+	return m.laps
+}
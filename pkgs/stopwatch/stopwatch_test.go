@@ -0,0 +1,194 @@
+package stopwatch
+
+import (
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// fakeClock is a clock that only moves when told to.
+type fakeClock struct {
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	return c.now
+}
+
+func (c *fakeClock) Advance(d time.Duration) {
+	c.now = c.now.Add(d)
+}
+
+func newTestModel() (Model, *fakeClock) {
+	clock := &fakeClock{now: time.Date(2026, 10, 19, 9, 0, 0, 0, time.UTC)}
+	m := NewWithInterval(0, time.Millisecond)
+	m.Clock = clock.Now
+	return m, clock
+}
+
+// send runs cmd and feeds the messages it produces back into m, except for
+// ticks, which the tests deliver themselves.
+func send(t *testing.T, m Model, cmd tea.Cmd) Model {
+	t.Helper()
+	if cmd == nil {
+		return m
+	}
+	switch msg := cmd().(type) {
+	case tea.BatchMsg:
+		for _, cmd := range msg {
+			m = send(t, m, cmd)
+		}
+	case TickMsg:
+	default:
+		m, _ = m.Update(msg)
+	}
+	return m
+}
+
+func assertElapsed(t *testing.T, m Model, want time.Duration) {
+	t.Helper()
+	if got := m.Elapsed(); got != want {
+		t.Errorf("Elapsed() = %v, want %v", got, want)
+	}
+}
+
+func TestStartStop(t *testing.T) {
+	m, clock := newTestModel()
+
+	clock.Advance(time.Minute)
+	assertElapsed(t, m, 0)
+
+	m = send(t, m, m.Start())
+	if !m.Running() {
+		t.Fatal("stopwatch not running after Start")
+	}
+	clock.Advance(10 * time.Second)
+	assertElapsed(t, m, 10*time.Second)
+
+	m = send(t, m, m.Stop())
+	if m.Running() {
+		t.Fatal("stopwatch running after Stop")
+	}
+	clock.Advance(time.Hour)
+	assertElapsed(t, m, 10*time.Second)
+
+	m = send(t, m, m.Start())
+	clock.Advance(5 * time.Second)
+	assertElapsed(t, m, 15*time.Second)
+}
+
+func TestToggle(t *testing.T) {
+	m, clock := newTestModel()
+
+	m = send(t, m, m.Toggle())
+	if !m.Running() {
+		t.Fatal("Toggle did not start a stopped stopwatch")
+	}
+	clock.Advance(3 * time.Second)
+
+	m = send(t, m, m.Toggle())
+	if m.Running() {
+		t.Fatal("Toggle did not stop a running stopwatch")
+	}
+	clock.Advance(time.Minute)
+	assertElapsed(t, m, 3*time.Second)
+}
+
+func TestReset(t *testing.T) {
+	m, clock := newTestModel()
+
+	m = send(t, m, m.Start())
+	clock.Advance(time.Minute)
+	m = m.Lap("first")
+
+	m = send(t, m, m.Reset())
+	assertElapsed(t, m, 0)
+	if len(m.Laps()) != 0 {
+		t.Errorf("Laps() = %v after Reset, want none", m.Laps())
+	}
+	if !m.Running() {
+		t.Fatal("Reset stopped a running stopwatch")
+	}
+
+	clock.Advance(2 * time.Second)
+	assertElapsed(t, m, 2*time.Second)
+}
+
+func TestElapsedFollowsClockNotTicks(t *testing.T) {
+	m, clock := newTestModel()
+	m = send(t, m, m.Start())
+
+	// A single tick arrives long after it was due, e.g. after the machine
+	// was suspended, and all the ticks in between were missed.
+	clock.Advance(90 * time.Minute)
+	m, cmd := m.Update(TickMsg{ID: m.ID()})
+	if cmd == nil {
+		t.Error("tick did not schedule the next tick")
+	}
+	assertElapsed(t, m, 90*time.Minute)
+	if got, want := m.View(), "01h30m00s"; got != want {
+		t.Errorf("View() = %q, want %q", got, want)
+	}
+
+	// Extra ticks do not add time either.
+	for range 5 {
+		m, _ = m.Update(TickMsg{ID: m.ID()})
+	}
+	assertElapsed(t, m, 90*time.Minute)
+}
+
+func TestTickFromOtherStopwatch(t *testing.T) {
+	m, _ := newTestModel()
+	m = send(t, m, m.Start())
+
+	if _, cmd := m.Update(TickMsg{ID: m.ID() + 1}); cmd != nil {
+		t.Error("tick of another stopwatch scheduled a tick")
+	}
+}
+
+func TestSharedTick(t *testing.T) {
+	m, clock := newTestModel()
+	m = m.Run()
+
+	clock.Advance(42 * time.Second)
+	m, cmd := m.Update(SharedTickMsg{})
+	if cmd != nil {
+		t.Error("shared tick scheduled a tick of its own")
+	}
+	assertElapsed(t, m, 42*time.Second)
+}
+
+func TestSetElapsedTime(t *testing.T) {
+	m, clock := newTestModel()
+
+	m = m.SetElapsedTime(time.Hour)
+	clock.Advance(time.Minute)
+	assertElapsed(t, m, time.Hour)
+
+	m = send(t, m, m.Start())
+	clock.Advance(time.Minute)
+	assertElapsed(t, m, time.Hour+time.Minute)
+
+	// On a running stopwatch the time elapsed before is replaced and
+	// counting goes on from the new value.
+	m = m.SetElapsedTime(10 * time.Second)
+	assertElapsed(t, m, 10*time.Second)
+	clock.Advance(5 * time.Second)
+	assertElapsed(t, m, 15*time.Second)
+}
+
+func TestLapUsesClock(t *testing.T) {
+	m, clock := newTestModel()
+	m = send(t, m, m.Start())
+
+	clock.Advance(2 * time.Minute)
+	m = m.Lap("setup")
+	clock.Advance(3 * time.Minute)
+	m = m.Lap("")
+
+	laps := m.Laps()
+	if len(laps) != 2 || laps[0].Elapsed != 2*time.Minute || laps[1].Elapsed != 5*time.Minute || laps[0].Note != "setup" {
+		t.Errorf("Laps() = %v, want laps at 2m0s (setup) and 5m0s", laps)
+	}
+}
@@ -8,6 +8,8 @@ import (
 
 	"github.com/charmbracelet/huh"
 	"github.com/charmbracelet/huh/spinner"
+
+	"go-time/pkgs/util"
 )
 
 func Form() *huh.Form {
@@ -15,7 +17,8 @@ func Form() *huh.Form {
 		huh.NewGroup(
 			huh.NewInput().
 				Key("name").
-				Title("Name"),
+				Title("Name").
+				Validate(util.ValidateNotEmpty),
 		),
 	)
 }
@@ -26,7 +29,8 @@ func EditForm(tag Tag) *huh.Form {
 			huh.NewInput().
 				Key("name").
 				Title("Name").
-				Value(&tag.Name),
+				Value(&tag.Name).
+				Validate(util.ValidateNotEmpty),
 		),
 	)
 }
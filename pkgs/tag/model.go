@@ -16,7 +16,17 @@ type Tag struct {
 	Name string `json:"name"`
 }
 
+// Names returns the names of tags.
+func Names(tags []Tag) []string {
+	return util.Map(tags, func(tag Tag) string {
+		return tag.Name
+	})
+}
+
 func CreateTag(ctx context.Context, db *sql.DB, name string) error {
+	if name == "" {
+		return fmt.Errorf("name cannot be empty")
+	}
 	_, err := db.Exec("INSERT INTO tags (name) VALUES (?)", name)
 	if err != nil {
 		return fmt.Errorf("error inserting tag: %w", err)
@@ -53,6 +63,48 @@ func GetTags(ctx context.Context, db *sql.DB) ([]Tag, error) {
 	return tags, nil
 }
 
+func GetTagsForEntry(ctx context.Context, db *sql.DB, entryID int) ([]Tag, error) {
+	var tags []Tag
+	query := `
+    SELECT t.id, t.name
+    FROM tags t
+    INNER JOIN entry_tags et ON t.id = et.tag_id
+    WHERE et.entry_id = ?
+    ORDER BY t.name`
+
+	rows, err := db.QueryContext(ctx, query, entryID)
+	if err != nil {
+		return nil, fmt.Errorf("error querying entry tags: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var tag Tag
+		if err := rows.Scan(&tag.ID, &tag.Name); err != nil {
+			return nil, fmt.Errorf("error scanning tag: %w", err)
+		}
+		tags = append(tags, tag)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating over rows: %w", err)
+	}
+	return tags, nil
+}
+
+func EditTag(ctx context.Context, db *sql.DB, id int, name string) error {
+	if name == "" {
+		return fmt.Errorf("name cannot be empty")
+	}
+	res, err := db.ExecContext(ctx, "UPDATE tags SET name = ? WHERE id = ?", name, id)
+	if err != nil {
+		return fmt.Errorf("error updating tag: %w", err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return fmt.Errorf("tag %d not found", id)
+	}
+	return nil
+}
+
 func GetTagsAsStrArr(ctx context.Context, db *sql.DB) ([]string, error) {
 	dbTags, err := GetTags(ctx, db)
 	if err != nil {
@@ -64,3 +116,43 @@ func GetTagsAsStrArr(ctx context.Context, db *sql.DB) ([]string, error) {
 	})
 	return tagsStr, err
 }
+
+// GetTagsByEntry returns the tags of all entries, keyed by entry ID.
+func GetTagsByEntry(ctx context.Context, db *sql.DB) (map[int][]Tag, error) {
+	return tagsBy(ctx, db, `
+    SELECT et.entry_id, t.id, t.name
+    FROM tags t
+    INNER JOIN entry_tags et ON t.id = et.tag_id
+    ORDER BY t.name`)
+}
+
+// GetTagsByTimer returns the tags of all running timers, keyed by timer ID.
+func GetTagsByTimer(ctx context.Context, db *sql.DB) (map[int][]Tag, error) {
+	return tagsBy(ctx, db, `
+    SELECT tt.timer_id, t.id, t.name
+    FROM tags t
+    INNER JOIN timer_tags tt ON t.id = tt.tag_id
+    ORDER BY t.name`)
+}
+
+func tagsBy(ctx context.Context, db *sql.DB, query string) (map[int][]Tag, error) {
+	rows, err := db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("error querying tags: %w", err)
+	}
+	defer rows.Close()
+
+	tags := make(map[int][]Tag)
+	for rows.Next() {
+		var id int
+		var tag Tag
+		if err := rows.Scan(&id, &tag.ID, &tag.Name); err != nil {
+			return nil, fmt.Errorf("error scanning tag: %w", err)
+		}
+		tags[id] = append(tags[id], tag)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating over rows: %w", err)
+	}
+	return tags, nil
+}
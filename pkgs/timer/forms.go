@@ -5,6 +5,7 @@ import (
 	"database/sql"
 	"fmt"
 	"log"
+	"time"
 
 	"github.com/charmbracelet/huh"
 	"github.com/charmbracelet/huh/spinner"
@@ -13,54 +14,136 @@ import (
 	"go-time/pkgs/util"
 )
 
-func Form(tags []string) *huh.Form {
+// FormResult holds the validated values of a completed timer form.
+type FormResult struct {
+	Name        string
+	Description string
+	Project     string
+	StartTime   time.Time
+	Tags        []string
+}
+
+// Form asks for the name and tags of a new timer. If there are templates,
+// one can be picked to start from, in which case the name may be left
+// empty and the tags add to those of the template.
+func Form(tags []string, templates []Template) *huh.Form {
 	options := util.CreateTagOptions(tags)
-	return huh.NewForm(
-		huh.NewGroup(
-			huh.NewInput().Key("name").Title("Name"),
-			huh.NewMultiSelect[string]().Key("tags").Title("Tags").Options(options...).Limit(3).Value(&tags),
-		),
-	)
+	var selected []string
+	var template *Template
+
+	fields := []huh.Field{
+		huh.NewInput().Key("name").Title("Name").Validate(func(s string) error {
+			if template != nil {
+				return nil
+			}
+			return util.ValidateNotEmpty(s)
+		}),
+		huh.NewMultiSelect[string]().Key("tags").Title("Tags").Options(options...).Limit(3).Value(&selected),
+	}
+	if len(templates) > 0 {
+		templateOptions := []huh.Option[*Template]{huh.NewOption("None", (*Template)(nil))}
+		for i, t := range templates {
+			label := "@" + t.Name
+			if t.Project != "" {
+				label += " (" + t.Project + ")"
+			}
+			templateOptions = append(templateOptions, huh.NewOption(label, &templates[i]))
+		}
+		picker := huh.NewSelect[*Template]().Key("template").Title("Template").Options(templateOptions...).Value(&template)
+		fields = append([]huh.Field{picker}, fields...)
+	}
+	return huh.NewForm(huh.NewGroup(fields...))
 }
 
 func EditForm(timer Timer, tags []string) *huh.Form {
 	options := util.CreateTagOptions(tags)
+	selected := timer.Tags
 	return huh.NewForm(
 		huh.NewGroup(
-			huh.NewInput().Key("name").Title("Name").Value(&timer.Name),
-			huh.NewInput().Key("start_time").Title("Start Time").Value(util.TimePtrToStringPtr(&timer.StartTime)),
-			huh.NewMultiSelect[string]().Key("tags").Title("Tags").Options(options...).Limit(3).Value(&tags),
+			huh.NewInput().Key("name").Title("Name").Value(&timer.Name).Validate(util.ValidateNotEmpty),
+			huh.NewInput().Key("start_time").Title("Start Time (YYYY-MM-DD HH:MM:SS)").Value(util.TimePtrToStringPtr(&timer.StartTime)).
+				Validate(func(s string) error {
+					start, err := util.ParseTime(s)
+					if err != nil {
+						return err
+					}
+					if start.After(time.Now()) {
+						return fmt.Errorf("start time cannot be in the future")
+					}
+					return nil
+				}),
+			huh.NewMultiSelect[string]().Key("tags").Title("Tags").Options(options...).Limit(3).Value(&selected),
 		),
 	)
 }
 
+// ReadForm extracts the values of a completed Form or EditForm. The start
+// time is zero for forms without a start time field. A template picked in
+// the form provides the name if none was entered, its description, its
+// project and its tags.
+func ReadForm(form *huh.Form) (FormResult, error) {
+	result := FormResult{Name: form.GetString("name")}
+
+	tags, ok := form.Get("tags").([]string)
+	if !ok {
+		return result, fmt.Errorf("tags is not of type []string")
+	}
+	result.Tags = tags
+
+	if template, _ := form.Get("template").(*Template); template != nil {
+		if result.Name == "" {
+			result.Name = template.Name
+		}
+		result.Description = template.Description
+		result.Project = template.Project
+		result.Tags = mergeTags(template.Tags, tags)
+	}
+
+	if startTime := form.GetString("start_time"); startTime != "" {
+		start, err := util.ParseTime(startTime)
+		if err != nil {
+			return result, fmt.Errorf("error parsing start time: %w", err)
+		}
+		result.StartTime = start
+	}
+
+	if result.Name == "" {
+		return result, fmt.Errorf("name cannot be empty")
+	}
+	return result, nil
+}
+
 func HandleForm(ctx context.Context, db *sql.DB) {
 	tagsStr, err := tag.GetTagsAsStrArr(ctx, db)
 	if err != nil {
 		log.Printf("Error fetching tags: %v", err)
 		return
 	}
+	templates, err := GetTemplates(ctx, db)
+	if err != nil {
+		log.Printf("Error fetching templates: %v", err)
+		return
+	}
 
-	form := Form(tagsStr)
+	form := Form(tagsStr, templates)
 	if err = form.Run(); err != nil {
 		log.Printf("Error running timer form: %v", err)
 		return
 	}
 
-	name := form.GetString("name")
-	tags, ok := form.Get("tags").([]string)
-	if !ok {
-		fmt.Println("Error: tags is not of type []string")
+	result, err := ReadForm(form)
+	if err != nil {
+		fmt.Println("Error:", err)
 		return
 	}
 
 	spinner := spinner.New().Title("Creating timer...")
 	err = spinner.Action(func() {
-		err := CreateTimer(ctx, db, name, tags)
+		err := Start(ctx, db, result.Name, result.Description, result.Project, result.Tags)
 		if err != nil {
 			log.Printf("Error creating timer: %v", err)
 		} else {
-			fmt.Println("Timer started for task:", name)
+			fmt.Println("Timer started for task:", result.Name)
 		}
 	}).Run()
 
@@ -68,3 +151,84 @@ func HandleForm(ctx context.Context, db *sql.DB) {
 		fmt.Println("Error: ", err)
 	}
 }
+
+// LapForm asks for the note of a lap marked on a running timer.
+func LapForm() *huh.Form {
+	return huh.NewForm(
+		huh.NewGroup(
+			huh.NewInput().Key("note").Title("Lap note"),
+		),
+	)
+}
+
+// IdleSummary describes the idle periods of a timer, e.g. "No activity for
+// 0h25m since 14:02 while tracking review".
+func IdleSummary(idle []Idle) string {
+	var total time.Duration
+	for _, i := range idle {
+		total += i.Duration()
+	}
+	since := idle[0].Start.In(util.Location()).Format("15:04")
+	if len(idle) > 1 {
+		return fmt.Sprintf("No activity for %s in %d periods since %s while tracking %s",
+			util.FormatDuration(total), len(idle), since, idle[0].Name)
+	}
+	return fmt.Sprintf("No activity for %s since %s while tracking %s", util.FormatDuration(total), since, idle[0].Name)
+}
+
+// PromptIdle asks whether to discard, keep or split off the idle periods of
+// a timer.
+func PromptIdle(idle []Idle) (IdleAction, error) {
+	action := DiscardIdle
+	form := huh.NewForm(
+		huh.NewGroup(
+			huh.NewSelect[IdleAction]().Title(IdleSummary(idle)).Value(&action).Options(
+				huh.NewOption("Discard the idle time", DiscardIdle),
+				huh.NewOption("Keep the idle time", KeepIdle),
+				huh.NewOption("Split the idle time into an entry of its own", SplitIdle),
+			),
+		),
+	)
+	if err := form.Run(); err != nil {
+		return KeepIdle, fmt.Errorf("error running idle prompt: %w", err)
+	}
+	return action, nil
+}
+
+// RecoveryForm asks when a timer that ran for longer than limit really
+// stopped, suggesting the time the limit was reached.
+func RecoveryForm(timer Timer, limit time.Duration) *huh.Form {
+	end := util.FormatTime(timer.StartTime.Add(limit))
+	return huh.NewForm(
+		huh.NewGroup(
+			huh.NewNote().Title("Forgotten timer").Description(fmt.Sprintf(
+				"The timer for %s has been running since %s, longer than %s.\nWhen did you really stop working on it?",
+				timer.Name, util.FormatTime(timer.StartTime), util.FormatDuration(limit))),
+			huh.NewInput().Key("end_time").Title("End Time (YYYY-MM-DD HH:MM:SS)").Value(&end).
+				Validate(func(s string) error {
+					_, err := validateEnd(timer, s)
+					return err
+				}),
+		),
+	)
+}
+
+// ReadRecoveryForm returns the end time entered in a completed recovery
+// form for timer.
+func ReadRecoveryForm(form *huh.Form, timer Timer) (time.Time, error) {
+	return validateEnd(timer, form.GetString("end_time"))
+}
+
+func validateEnd(timer Timer, s string) (time.Time, error) {
+	end, err := util.ParseTime(s)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if !end.After(timer.StartTime) {
+		return time.Time{}, fmt.Errorf("end time must be after the start time")
+	}
+	if end.After(time.Now()) {
+		return time.Time{}, fmt.Errorf("end time cannot be in the future")
+	}
+	return end, nil
+}
@@ -0,0 +1,213 @@
+package timer
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"go-time/pkgs/util"
+	"log"
+	"time"
+)
+
+// IdleAction is what to do with the time a timer ran while nobody was
+// around.
+type IdleAction int
+
+const (
+	// KeepIdle counts idle time as tracked.
+	KeepIdle IdleAction = iota
+	// DiscardIdle leaves idle time out of the recorded entries.
+	DiscardIdle
+	// SplitIdle records idle time as entries of its own, described as
+	// "Idle", so that it can be sorted out later.
+	SplitIdle
+)
+
+var idleActions = []string{"keep", "discard", "split"}
+
+func (a IdleAction) String() string {
+	return idleActions[a]
+}
+
+// ParseIdleAction parses "keep", "discard" or "split".
+func ParseIdleAction(s string) (IdleAction, error) {
+	for i, name := range idleActions {
+		if s == name {
+			return IdleAction(i), nil
+		}
+	}
+	return KeepIdle, fmt.Errorf("unknown idle action %q, expected keep, discard or split", s)
+}
+
+// Idle is a period in which no heartbeat was seen while a timer ran.
+type Idle struct {
+	TimerID    int
+	Name       string
+	Start, End time.Time
+}
+
+// Duration returns the length of the idle period.
+func (i Idle) Duration() time.Duration {
+	return i.End.Sub(i.Start)
+}
+
+// Heartbeat records activity at now for every running timer. A gap of at
+// least threshold since the previous heartbeat is stored as an idle period
+// of the timer, to be resolved when it is stopped or resumed. It returns
+// the idle periods it stored. A threshold of zero disables idle detection.
+func Heartbeat(ctx context.Context, db *sql.DB, now time.Time, threshold time.Duration) ([]Idle, error) {
+	tx, err := db.BeginTx(ctx, &sql.TxOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("error starting transaction: %w", err)
+	}
+
+	defer func() {
+		if rbErr := tx.Rollback(); rbErr != nil && rbErr != sql.ErrTxDone {
+			log.Printf("transaction rollback error: %v", rbErr)
+		}
+	}()
+
+	rows, err := tx.QueryContext(ctx, "SELECT id, name, last_seen FROM timers WHERE is_running = 1")
+	if err != nil {
+		return nil, fmt.Errorf("error querying active timers: %w", err)
+	}
+	var idle []Idle
+	for rows.Next() {
+		var id int
+		var name string
+		var lastSeen sql.NullTime
+		if err := rows.Scan(&id, &name, &lastSeen); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("error scanning timer row: %w", err)
+		}
+		if i, ok := trailingIdle(id, name, lastSeen, now, threshold); ok {
+			idle = append(idle, i)
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating over timer rows: %w", err)
+	}
+
+	for _, i := range idle {
+		_, err := tx.ExecContext(ctx, "INSERT INTO timer_idle (timer_id, start_time, end_time) VALUES (?, ?, ?)",
+			i.TimerID, i.Start.UTC(), i.End.UTC())
+		if err != nil {
+			return nil, fmt.Errorf("error saving idle period: %w", err)
+		}
+	}
+	if _, err := tx.ExecContext(ctx, "UPDATE timers SET last_seen = ? WHERE is_running = 1", now.UTC()); err != nil {
+		return nil, fmt.Errorf("error updating heartbeat: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("error committing transaction: %w", err)
+	}
+	return idle, nil
+}
+
+// IdlePeriods returns the unresolved idle periods of the running timer for
+// timerName, including the time since its last heartbeat if that is at
+// least threshold.
+func IdlePeriods(ctx context.Context, db *sql.DB, timerName string, now time.Time, threshold time.Duration) ([]Idle, error) {
+	var id int
+	var lastSeen sql.NullTime
+	err := db.QueryRowContext(ctx, "SELECT id, last_seen FROM timers WHERE is_running = 1 AND name = ?", timerName).
+		Scan(&id, &lastSeen)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching running timer: %w", err)
+	}
+
+	idle, err := fetchIdleForTimer(ctx, db, id)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching idle periods for timer: %w", err)
+	}
+	if i, ok := trailingIdle(id, timerName, lastSeen, now, threshold); ok {
+		idle = append(idle, i)
+	}
+	return idle, nil
+}
+
+// Resume resolves the stored idle periods of the running timer for
+// timerName. Kept idle time stays part of the timer. Otherwise the time
+// tracked until the end of the last idle period is recorded as entries with
+// the idle time discarded or split off, and the timer starts over from
+// there. It returns the IDs of the new entries.
+func Resume(ctx context.Context, db *sql.DB, timerName string, action IdleAction) ([]int, error) {
+	tx, err := db.BeginTx(ctx, &sql.TxOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("error starting transaction: %w", err)
+	}
+
+	defer func() {
+		if rbErr := tx.Rollback(); rbErr != nil && rbErr != sql.ErrTxDone {
+			log.Printf("transaction rollback error: %v", rbErr)
+		}
+	}()
+
+	now := time.Now()
+	r, err := loadRun(ctx, tx, timerName, now, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	var entryIDs []int
+	if action == KeepIdle {
+		_, err = tx.ExecContext(ctx, "DELETE FROM timer_idle WHERE timer_id = ?", r.id)
+		if err != nil {
+			return nil, fmt.Errorf("error deleting idle periods: %w", err)
+		}
+	} else {
+		// The timer starts over when the activity resumed, which ends the
+		// last idle period.
+		if n := len(r.idle); n > 0 && r.idle[n-1].End.Before(r.end) {
+			r.end = r.idle[n-1].End
+		}
+		if entryIDs, err = r.record(ctx, tx, false, action); err != nil {
+			return nil, err
+		}
+		if err := r.clear(ctx, tx); err != nil {
+			return nil, err
+		}
+		_, err = tx.ExecContext(ctx, "UPDATE timers SET start_time = ?, start_offset = ? WHERE id = ?",
+			r.end.UTC(), util.ZoneOffset(r.end), r.id)
+		if err != nil {
+			return nil, fmt.Errorf("error restarting timer: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("error committing transaction: %w", err)
+	}
+	return entryIDs, nil
+}
+
+// trailingIdle returns the time from the last heartbeat of a timer until
+// now as an idle period if it is at least threshold. Timers that never
+// had a heartbeat are not considered idle.
+func trailingIdle(timerID int, name string, lastSeen sql.NullTime, now time.Time, threshold time.Duration) (Idle, bool) {
+	if threshold <= 0 || !lastSeen.Valid || now.Sub(lastSeen.Time) < threshold {
+		return Idle{}, false
+	}
+	return Idle{TimerID: timerID, Name: name, Start: lastSeen.Time.In(util.Location()), End: now}, true
+}
+
+func fetchIdleForTimer(ctx context.Context, tx querier, timerID int) ([]Idle, error) {
+	rows, err := tx.QueryContext(ctx, "SELECT i.start_time, i.end_time, t.name FROM timer_idle i "+
+		"INNER JOIN timers t ON t.id = i.timer_id WHERE i.timer_id = ? ORDER BY i.start_time", timerID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var idle []Idle
+	for rows.Next() {
+		i := Idle{TimerID: timerID}
+		if err := rows.Scan(&i.Start, &i.End, &i.Name); err != nil {
+			return nil, err
+		}
+		i.Start, i.End = i.Start.In(util.Location()), i.End.In(util.Location())
+		idle = append(idle, i)
+	}
+	return idle, rows.Err()
+}
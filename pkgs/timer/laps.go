@@ -0,0 +1,97 @@
+package timer
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"go-time/pkgs/util"
+	"strings"
+	"time"
+)
+
+// Lap is a timestamped note marking the end of a phase of a running timer.
+type Lap struct {
+	ID      int
+	TimerID int
+	Time    time.Time
+	Note    string
+}
+
+// AddLap marks a lap with note on the running timer for timerName.
+func AddLap(ctx context.Context, db *sql.DB, timerName, note string) (Lap, error) {
+	lap := Lap{Time: time.Now(), Note: note}
+	err := db.QueryRowContext(ctx, "SELECT id FROM timers WHERE is_running = 1 AND name = ?", timerName).Scan(&lap.TimerID)
+	if err == sql.ErrNoRows {
+		return Lap{}, fmt.Errorf("no timer running for task: %s", timerName)
+	}
+	if err != nil {
+		return Lap{}, fmt.Errorf("error fetching running timer: %w", err)
+	}
+
+	res, err := db.ExecContext(ctx, "INSERT INTO timer_laps (timer_id, lap_time, lap_offset, note) VALUES (?, ?, ?, ?)",
+		lap.TimerID, lap.Time.UTC(), util.ZoneOffset(lap.Time), note)
+	if err != nil {
+		return Lap{}, fmt.Errorf("error saving lap: %w", err)
+	}
+	id, err := res.LastInsertId()
+	if err != nil {
+		return Lap{}, fmt.Errorf("error getting last insert ID: %w", err)
+	}
+	lap.ID = int(id)
+	return lap, nil
+}
+
+// GetLapsByTimer returns the laps of every running timer in the order they
+// were marked, by timer ID.
+func GetLapsByTimer(ctx context.Context, db *sql.DB) (map[int][]Lap, error) {
+	laps, err := fetchLaps(ctx, db, "SELECT l.id, l.timer_id, l.lap_time, l.lap_offset, l.note FROM timer_laps l "+
+		"INNER JOIN timers t ON t.id = l.timer_id WHERE t.is_running = 1 ORDER BY l.lap_time, l.id")
+	if err != nil {
+		return nil, fmt.Errorf("error fetching laps: %w", err)
+	}
+
+	byTimer := make(map[int][]Lap)
+	for _, lap := range laps {
+		byTimer[lap.TimerID] = append(byTimer[lap.TimerID], lap)
+	}
+	return byTimer, nil
+}
+
+func fetchLaps(ctx context.Context, tx querier, query string, args ...any) ([]Lap, error) {
+	rows, err := tx.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var laps []Lap
+	for rows.Next() {
+		var lap Lap
+		var offset int
+		var note sql.NullString
+		if err := rows.Scan(&lap.ID, &lap.TimerID, &lap.Time, &offset, &note); err != nil {
+			return nil, err
+		}
+		lap.Time = util.InOffset(lap.Time, offset)
+		lap.Note = note.String
+		laps = append(laps, lap)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return laps, nil
+}
+
+func fetchLapsForTimer(ctx context.Context, tx querier, timerID int) ([]Lap, error) {
+	return fetchLaps(ctx, tx, "SELECT id, timer_id, lap_time, lap_offset, note FROM timer_laps WHERE timer_id = ? ORDER BY lap_time, id", timerID)
+}
+
+// lapDescription lists the laps as "15:04 note" for the description of the
+// entry of a stopped timer.
+func lapDescription(laps []Lap) string {
+	lines := make([]string, len(laps))
+	for i, lap := range laps {
+		lines[i] = strings.TrimSpace(lap.Time.In(util.Location()).Format("15:04") + " " + lap.Note)
+	}
+	return strings.Join(lines, "; ")
+}
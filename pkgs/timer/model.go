@@ -4,7 +4,7 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
-	"go-time/pkgs/entry"
+	"go-time/pkgs/util"
 	"log"
 	"time"
 )
@@ -14,6 +14,17 @@ type Timer struct {
 	Name      string
 	StartTime time.Time
 	Tags      []string
+	Laps      []Lap
+
+	// StartOffset is the offset from UTC in seconds of the zone the timer
+	// was started in. StartTime itself is stored in UTC.
+	StartOffset int
+}
+
+// Overdue reports whether the timer has run for longer than limit at now,
+// which suggests that it was forgotten. A limit of zero is never exceeded.
+func (t Timer) Overdue(now time.Time, limit time.Duration) bool {
+	return limit > 0 && now.Sub(t.StartTime) > limit
 }
 
 type TimerState struct {
@@ -33,7 +44,7 @@ func IsTimerRunning(ctx context.Context, db *sql.DB, timerName string) (bool, er
 }
 
 func ReadTimers(ctx context.Context, db *sql.DB) ([]Timer, error) {
-	query := "SELECT id, name, start_time FROM timers WHERE is_running = 1"
+	query := "SELECT id, name, start_time, start_offset FROM timers WHERE is_running = 1"
 	rows, err := db.QueryContext(ctx, query)
 	if err != nil {
 		return nil, fmt.Errorf("error querying active timers: %w", err)
@@ -43,7 +54,7 @@ func ReadTimers(ctx context.Context, db *sql.DB) ([]Timer, error) {
 	var timers []Timer
 	for rows.Next() {
 		var timer Timer
-		if err := rows.Scan(&timer.ID, &timer.Name, &timer.StartTime); err != nil {
+		if err := rows.Scan(&timer.ID, &timer.Name, &timer.StartTime, &timer.StartOffset); err != nil {
 			return nil, fmt.Errorf("error scanning timer row: %w", err)
 		}
 		timers = append(timers, timer)
@@ -54,7 +65,51 @@ func ReadTimers(ctx context.Context, db *sql.DB) ([]Timer, error) {
 	return timers, nil
 }
 
+// GetTimer returns a single running timer together with its tags and laps.
+func GetTimer(ctx context.Context, db *sql.DB, id int) (Timer, error) {
+	var timer Timer
+	err := db.QueryRowContext(ctx, "SELECT id, name, start_time, start_offset FROM timers WHERE id = ?", id).
+		Scan(&timer.ID, &timer.Name, &timer.StartTime, &timer.StartOffset)
+	if err == sql.ErrNoRows {
+		return Timer{}, fmt.Errorf("timer %d not found", id)
+	}
+	if err != nil {
+		return Timer{}, fmt.Errorf("error reading timer: %w", err)
+	}
+
+	timer.Tags, err = fetchTagsForTimer(ctx, db, id)
+	if err != nil {
+		return Timer{}, fmt.Errorf("error fetching tags for timer: %w", err)
+	}
+	timer.Laps, err = fetchLapsForTimer(ctx, db, id)
+	if err != nil {
+		return Timer{}, fmt.Errorf("error fetching laps for timer: %w", err)
+	}
+	return timer, nil
+}
+
+// GetRunningTimer returns the running timer for timerName together with its
+// tags and laps.
+func GetRunningTimer(ctx context.Context, db *sql.DB, timerName string) (Timer, error) {
+	var id int
+	err := db.QueryRowContext(ctx, "SELECT id FROM timers WHERE is_running = 1 AND name = ?", timerName).Scan(&id)
+	if err == sql.ErrNoRows {
+		return Timer{}, fmt.Errorf("no timer running for task: %s", timerName)
+	}
+	if err != nil {
+		return Timer{}, fmt.Errorf("error fetching running timer: %w", err)
+	}
+	return GetTimer(ctx, db, id)
+}
+
 func CreateTimer(ctx context.Context, db *sql.DB, timerName string, tags []string) error {
+	return Start(ctx, db, timerName, "", "", tags)
+}
+
+// Start starts a timer for timerName with tags. The entries recorded when it
+// stops are described by description and belong to project, if they are not
+// empty.
+func Start(ctx context.Context, db *sql.DB, timerName, description, project string, tags []string) error {
 	isRunning, err := IsTimerRunning(ctx, db, timerName)
 	if err != nil {
 		return fmt.Errorf("error checking if timer is running: %w", err)
@@ -64,7 +119,9 @@ func CreateTimer(ctx context.Context, db *sql.DB, timerName string, tags []strin
 	}
 
 	startTime := time.Now()
-	res, err := db.ExecContext(ctx, "INSERT INTO timers (is_running, name, start_time) VALUES (?, ?, ?)", true, timerName, startTime)
+	res, err := db.ExecContext(ctx, "INSERT INTO timers (is_running, name, start_time, start_offset, description, project) VALUES (?, ?, ?, ?, ?, ?)",
+		true, timerName, startTime.UTC(), util.ZoneOffset(startTime), sql.NullString{String: description, Valid: description != ""},
+		sql.NullString{String: project, Valid: project != ""})
 	if err != nil {
 		return fmt.Errorf("error starting timer: %w", err)
 	}
@@ -96,7 +153,86 @@ func CreateTimer(ctx context.Context, db *sql.DB, timerName string, tags []strin
 	return nil
 }
 
-func StopTimer(ctx context.Context, db *sql.DB, timerName string) error {
+// StopTimer stops the running timer for timerName and records it as an
+// entry, returning the new entry's ID. The laps of the timer are listed in
+// the entry's description and idle time is kept.
+func StopTimer(ctx context.Context, db *sql.DB, timerName string) (int, error) {
+	ids, err := Stop(ctx, db, timerName, StopOptions{})
+	if err != nil || len(ids) == 0 {
+		return 0, err
+	}
+	return ids[0], nil
+}
+
+// StopOptions control how the time of a stopped timer is recorded.
+type StopOptions struct {
+	// Split records an entry for each lap, described by the lap's note, and
+	// one for the time after the last lap.
+	Split bool
+	// IdleThreshold is how long no heartbeat must be seen before the time
+	// up to the stop counts as idle. Zero disables idle detection.
+	IdleThreshold time.Duration
+	// Idle is what to do with the idle periods of the timer.
+	Idle IdleAction
+	// End is when the timer really stopped, e.g. for a timer that was
+	// forgotten. The zero time stops it now.
+	End time.Time
+}
+
+// Stop stops the running timer for timerName and records its time as
+// entries according to opts, returning the IDs of the new entries in order.
+func Stop(ctx context.Context, db *sql.DB, timerName string, opts StopOptions) ([]int, error) {
+	tx, err := db.BeginTx(ctx, &sql.TxOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("error starting transaction: %w", err)
+	}
+
+	defer func() {
+		if rbErr := tx.Rollback(); rbErr != nil && rbErr != sql.ErrTxDone {
+			log.Printf("transaction rollback error: %v", rbErr)
+		}
+	}()
+
+	end := time.Now()
+	if !opts.End.IsZero() {
+		if opts.End.After(end) {
+			return nil, fmt.Errorf("end time cannot be in the future")
+		}
+		end = opts.End
+	}
+
+	r, err := loadRun(ctx, tx, timerName, end, opts.IdleThreshold)
+	if err != nil {
+		return nil, err
+	}
+
+	entryIDs, err := r.record(ctx, tx, opts.Split, opts.Idle)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err = tx.ExecContext(ctx, "UPDATE timers SET is_running = 0 WHERE id = ?", r.id); err != nil {
+		return nil, fmt.Errorf("error updating timer state: %w", err)
+	}
+	if err := r.clear(ctx, tx); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("error committing transaction: %w", err)
+	}
+
+	return entryIDs, nil
+}
+
+func EditTimer(ctx context.Context, db *sql.DB, id int, name string, start time.Time, tags []string) error {
+	if name == "" {
+		return fmt.Errorf("name cannot be empty")
+	}
+	if start.After(time.Now()) {
+		return fmt.Errorf("start time cannot be in the future")
+	}
+
 	tx, err := db.BeginTx(ctx, &sql.TxOptions{})
 	if err != nil {
 		return fmt.Errorf("error starting transaction: %w", err)
@@ -108,25 +244,45 @@ func StopTimer(ctx context.Context, db *sql.DB, timerName string) error {
 		}
 	}()
 
-	var startTime time.Time
-	var timerID int
-	err = tx.QueryRowContext(ctx, "SELECT id, start_time FROM timers WHERE is_running = 1 AND name = ?", timerName).Scan(&timerID, &startTime)
+	var count int
+	err = tx.QueryRowContext(ctx, "SELECT COUNT(*) FROM timers WHERE is_running = 1 AND name = ? AND id != ?", name, id).Scan(&count)
 	if err != nil {
-		return fmt.Errorf("error fetching running timer: %w", err)
+		return fmt.Errorf("error checking timer state: %w", err)
+	}
+	if count > 0 {
+		return fmt.Errorf("timer is already running for task: %s", name)
 	}
 
-	tags, err := fetchTagsForTimer(ctx, tx, timerID)
+	res, err := tx.ExecContext(ctx, "UPDATE timers SET name = ?, start_time = ?, start_offset = ? WHERE id = ?",
+		name, start.UTC(), util.ZoneOffset(start), id)
 	if err != nil {
-		return fmt.Errorf("error fetching tags for timer: %w", err)
+		return fmt.Errorf("error updating timer: %w", err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return fmt.Errorf("timer %d not found", id)
 	}
 
-	endTime := time.Now()
-	if err = entry.CreateEntry(ctx, tx, timerName, startTime, endTime, tags); err != nil {
-		return fmt.Errorf("error saving time entry: %w", err)
+	if _, err = tx.ExecContext(ctx, "DELETE FROM timer_tags WHERE timer_id = ?", id); err != nil {
+		return fmt.Errorf("error deleting existing tags: %w", err)
 	}
 
-	if _, err = tx.ExecContext(ctx, "UPDATE timers SET is_running = 0 WHERE name = ?", timerName); err != nil {
-		return fmt.Errorf("error updating timer state: %w", err)
+	for _, tag := range tags {
+		var tagID int
+
+		_, err = tx.ExecContext(ctx, "INSERT OR IGNORE INTO tags (name) VALUES (?)", tag)
+		if err != nil {
+			return fmt.Errorf("error inserting tag: %w", err)
+		}
+
+		err = tx.QueryRowContext(ctx, "SELECT id FROM tags WHERE name = ?", tag).Scan(&tagID)
+		if err != nil {
+			return fmt.Errorf("error getting tag ID: %w", err)
+		}
+
+		_, err = tx.ExecContext(ctx, "INSERT INTO timer_tags (timer_id, tag_id) VALUES (?, ?)", id, tagID)
+		if err != nil {
+			return fmt.Errorf("error linking tag with timer: %w", err)
+		}
 	}
 
 	if err := tx.Commit(); err != nil {
@@ -153,6 +309,16 @@ func DeleteTimer(ctx context.Context, db *sql.DB, timerID int) error {
 		return fmt.Errorf("error deleting timer tags: %w", err)
 	}
 
+	_, err = tx.ExecContext(ctx, "DELETE FROM timer_laps WHERE timer_id = ?", timerID)
+	if err != nil {
+		return fmt.Errorf("error deleting timer laps: %w", err)
+	}
+
+	_, err = tx.ExecContext(ctx, "DELETE FROM timer_idle WHERE timer_id = ?", timerID)
+	if err != nil {
+		return fmt.Errorf("error deleting idle periods: %w", err)
+	}
+
 	_, err = tx.ExecContext(ctx, "DELETE FROM timers WHERE id = ?", timerID)
 	if err != nil {
 		return fmt.Errorf("error deleting timer: %w", err)
@@ -165,7 +331,11 @@ func DeleteTimer(ctx context.Context, db *sql.DB, timerID int) error {
 	return nil
 }
 
-func fetchTagsForTimer(ctx context.Context, tx *sql.Tx, timerID int) ([]string, error) {
+type querier interface {
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+}
+
+func fetchTagsForTimer(ctx context.Context, tx querier, timerID int) ([]string, error) {
 	var tags []string
 	query := `
     SELECT t.name 
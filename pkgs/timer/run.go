@@ -0,0 +1,191 @@
+package timer
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"go-time/pkgs/entry"
+	"go-time/pkgs/util"
+	"slices"
+	"time"
+)
+
+// run is the time tracked by a running timer up to end, with everything
+// needed to record it as entries.
+type run struct {
+	id          int
+	name        string
+	description string
+	project     string
+	start, end  time.Time
+	tags        []string
+	laps        []Lap
+	idle        []Idle
+}
+
+// loadRun reads the running timer for timerName as tracked until now,
+// leaving out laps marked after it. Its idle periods include the time since
+// the last heartbeat if that is at least threshold.
+func loadRun(ctx context.Context, tx *sql.Tx, timerName string, now time.Time, threshold time.Duration) (run, error) {
+	r := run{name: timerName, end: now}
+	var startOffset int
+	var lastSeen sql.NullTime
+	var description, project sql.NullString
+	err := tx.QueryRowContext(ctx, "SELECT id, start_time, start_offset, last_seen, description, project FROM timers WHERE is_running = 1 AND name = ?", timerName).
+		Scan(&r.id, &r.start, &startOffset, &lastSeen, &description, &project)
+	if err != nil {
+		return run{}, fmt.Errorf("error fetching running timer: %w", err)
+	}
+	r.start = util.InOffset(r.start, startOffset)
+	r.description = description.String
+	r.project = project.String
+
+	r.tags, err = fetchTagsForTimer(ctx, tx, r.id)
+	if err != nil {
+		return run{}, fmt.Errorf("error fetching tags for timer: %w", err)
+	}
+
+	laps, err := fetchLapsForTimer(ctx, tx, r.id)
+	if err != nil {
+		return run{}, fmt.Errorf("error fetching laps for timer: %w", err)
+	}
+	for _, lap := range laps {
+		if !lap.Time.After(now) {
+			r.laps = append(r.laps, lap)
+		}
+	}
+
+	r.idle, err = fetchIdleForTimer(ctx, tx, r.id)
+	if err != nil {
+		return run{}, fmt.Errorf("error fetching idle periods for timer: %w", err)
+	}
+	if idle, ok := trailingIdle(r.id, timerName, lastSeen, now, threshold); ok {
+		r.idle = mergeIdle(append(r.idle, idle))
+	}
+	return r, nil
+}
+
+// mergeIdle joins overlapping idle periods, which are sorted by start.
+func mergeIdle(idle []Idle) []Idle {
+	var merged []Idle
+	for _, i := range idle {
+		if n := len(merged); n > 0 && !i.Start.After(merged[n-1].End) {
+			if i.End.After(merged[n-1].End) {
+				merged[n-1].End = i.End
+			}
+			continue
+		}
+		merged = append(merged, i)
+	}
+	return merged
+}
+
+// record saves the run as entries, one for each lap if split is true, and
+// handles its idle periods according to action.
+func (r run) record(ctx context.Context, tx *sql.Tx, split bool, action IdleAction) ([]int, error) {
+	var entryIDs []int
+	for _, s := range r.segments(split, action) {
+		entryID, err := entry.CreateEntry(ctx, tx, r.name, s.note, r.project, s.start, s.end, r.tags)
+		if err != nil {
+			return nil, fmt.Errorf("error saving time entry: %w", err)
+		}
+		entryIDs = append(entryIDs, entryID)
+	}
+	return entryIDs, nil
+}
+
+// clear removes the laps and idle periods of the run once it is recorded.
+func (r run) clear(ctx context.Context, tx *sql.Tx) error {
+	if _, err := tx.ExecContext(ctx, "DELETE FROM timer_laps WHERE timer_id = ?", r.id); err != nil {
+		return fmt.Errorf("error deleting timer laps: %w", err)
+	}
+	if _, err := tx.ExecContext(ctx, "DELETE FROM timer_idle WHERE timer_id = ?", r.id); err != nil {
+		return fmt.Errorf("error deleting idle periods: %w", err)
+	}
+	return nil
+}
+
+// segment is a part of a run that is recorded as one entry.
+type segment struct {
+	start, end time.Time
+	note       string
+}
+
+// segments divides the run into the entries to record. Without split the
+// whole run is one entry described by the timer and its laps. With split,
+// only the part after the last lap, which has no note of its own, takes the
+// description of the timer. Discarded idle time is cut out of the entries,
+// and split idle time is recorded as entries of its own.
+func (r run) segments(split bool, action IdleAction) []segment {
+	note := lapDescription(r.laps)
+	if r.description != "" && note != "" {
+		note = r.description + "; " + note
+	} else if r.description != "" {
+		note = r.description
+	}
+	segments := []segment{{start: r.start, end: r.end, note: note}}
+	if split {
+		segments = splitAtLaps(r.start, r.end, r.laps)
+		if last := &segments[len(segments)-1]; last.note == "" {
+			last.note = r.description
+		}
+	}
+	if action == KeepIdle {
+		return segments
+	}
+
+	for _, idle := range r.idle {
+		segments = cutOut(segments, idle)
+	}
+	if action == SplitIdle {
+		for _, idle := range r.idle {
+			start, end := idle.Start, idle.End
+			if start.Before(r.start) {
+				start = r.start
+			}
+			if end.After(r.end) {
+				end = r.end
+			}
+			if end.After(start) {
+				segments = append(segments, segment{start: start, end: end, note: "Idle"})
+			}
+		}
+		slices.SortFunc(segments, func(a, b segment) int { return a.start.Compare(b.start) })
+	}
+	return segments
+}
+
+// splitAtLaps divides the run from start to end at each lap. Every segment
+// but the last ends at a lap and takes its note. Laps outside the run and
+// segments that would be empty are dropped.
+func splitAtLaps(start, end time.Time, laps []Lap) []segment {
+	var segments []segment
+	from := start
+	for _, lap := range laps {
+		if !lap.Time.After(from) || !lap.Time.Before(end) {
+			continue
+		}
+		segments = append(segments, segment{start: from, end: lap.Time, note: lap.Note})
+		from = lap.Time
+	}
+	return append(segments, segment{start: from, end: end})
+}
+
+// cutOut removes the idle period from the segments, keeping the parts
+// before and after it.
+func cutOut(segments []segment, idle Idle) []segment {
+	var kept []segment
+	for _, s := range segments {
+		if !idle.Start.Before(s.end) || !idle.End.After(s.start) {
+			kept = append(kept, s)
+			continue
+		}
+		if idle.Start.After(s.start) {
+			kept = append(kept, segment{start: s.start, end: idle.Start, note: s.note})
+		}
+		if idle.End.Before(s.end) {
+			kept = append(kept, segment{start: idle.End, end: s.end, note: s.note})
+		}
+	}
+	return kept
+}
@@ -0,0 +1,202 @@
+package timer
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"slices"
+	"strings"
+)
+
+// Template is a named preset for starting timers for a task that comes up
+// again and again. Timers started from it are named after it and get its
+// tags, description and project.
+type Template struct {
+	ID          int
+	Name        string
+	Description string
+	Project     string
+	Tags        []string
+}
+
+// Start starts a timer from the template, with extra tags in addition to
+// its own.
+func (t Template) Start(ctx context.Context, db *sql.DB, extra []string) error {
+	return Start(ctx, db, t.Name, t.Description, t.Project, mergeTags(t.Tags, extra))
+}
+
+// TemplateAlias returns the name of the template referred to by s as
+// "@name", and whether s refers to a template at all.
+func TemplateAlias(s string) (string, bool) {
+	if len(s) > 1 && strings.HasPrefix(s, "@") {
+		return s[1:], true
+	}
+	return "", false
+}
+
+// CreateTemplate saves a new template.
+func CreateTemplate(ctx context.Context, db *sql.DB, t Template) error {
+	if strings.TrimSpace(t.Name) == "" {
+		return fmt.Errorf("name cannot be empty")
+	}
+	if strings.HasPrefix(t.Name, "@") {
+		return fmt.Errorf("name cannot start with @")
+	}
+
+	tx, err := db.BeginTx(ctx, &sql.TxOptions{})
+	if err != nil {
+		return fmt.Errorf("error starting transaction: %w", err)
+	}
+
+	defer func() {
+		if rbErr := tx.Rollback(); rbErr != nil && rbErr != sql.ErrTxDone {
+			log.Printf("transaction rollback error: %v", rbErr)
+		}
+	}()
+
+	var exists bool
+	if err := tx.QueryRowContext(ctx, "SELECT EXISTS (SELECT 1 FROM templates WHERE name = ?)", t.Name).Scan(&exists); err != nil {
+		return fmt.Errorf("error checking for template: %w", err)
+	}
+	if exists {
+		return fmt.Errorf("template %q already exists", t.Name)
+	}
+
+	res, err := tx.ExecContext(ctx, "INSERT INTO templates (name, description, project) VALUES (?, ?, ?)",
+		t.Name, sql.NullString{String: t.Description, Valid: t.Description != ""}, sql.NullString{String: t.Project, Valid: t.Project != ""})
+	if err != nil {
+		return fmt.Errorf("error saving template: %w", err)
+	}
+	templateID, err := res.LastInsertId()
+	if err != nil {
+		return fmt.Errorf("error getting last insert ID: %w", err)
+	}
+
+	for _, tag := range t.Tags {
+		if _, err := tx.ExecContext(ctx, "INSERT OR IGNORE INTO tags (name) VALUES (?)", tag); err != nil {
+			return fmt.Errorf("error inserting tag: %w", err)
+		}
+		_, err := tx.ExecContext(ctx, "INSERT OR IGNORE INTO template_tags (template_id, tag_id) SELECT ?, id FROM tags WHERE name = ?",
+			templateID, tag)
+		if err != nil {
+			return fmt.Errorf("error linking tag with template: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("error committing transaction: %w", err)
+	}
+	return nil
+}
+
+// GetTemplates returns all templates ordered by name.
+func GetTemplates(ctx context.Context, db *sql.DB) ([]Template, error) {
+	rows, err := db.QueryContext(ctx, "SELECT id, name, description, project FROM templates ORDER BY name")
+	if err != nil {
+		return nil, fmt.Errorf("error querying templates: %w", err)
+	}
+	defer rows.Close()
+
+	var templates []Template
+	for rows.Next() {
+		var t Template
+		var description, project sql.NullString
+		if err := rows.Scan(&t.ID, &t.Name, &description, &project); err != nil {
+			return nil, fmt.Errorf("error scanning template row: %w", err)
+		}
+		t.Description = description.String
+		t.Project = project.String
+		templates = append(templates, t)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating over template rows: %w", err)
+	}
+	rows.Close()
+
+	for i := range templates {
+		if templates[i].Tags, err = fetchTagsForTemplate(ctx, db, templates[i].ID); err != nil {
+			return nil, fmt.Errorf("error fetching tags for template: %w", err)
+		}
+	}
+	return templates, nil
+}
+
+// GetTemplate returns the template called name.
+func GetTemplate(ctx context.Context, db *sql.DB, name string) (Template, error) {
+	templates, err := GetTemplates(ctx, db)
+	if err != nil {
+		return Template{}, err
+	}
+	for _, t := range templates {
+		if t.Name == name {
+			return t, nil
+		}
+	}
+	return Template{}, fmt.Errorf("no template named %q", name)
+}
+
+// DeleteTemplate deletes the template called name.
+func DeleteTemplate(ctx context.Context, db *sql.DB, name string) error {
+	tx, err := db.BeginTx(ctx, &sql.TxOptions{})
+	if err != nil {
+		return fmt.Errorf("error starting transaction: %w", err)
+	}
+
+	defer func() {
+		if rbErr := tx.Rollback(); rbErr != nil && rbErr != sql.ErrTxDone {
+			log.Printf("transaction rollback error: %v", rbErr)
+		}
+	}()
+
+	var id int
+	err = tx.QueryRowContext(ctx, "SELECT id FROM templates WHERE name = ?", name).Scan(&id)
+	if err == sql.ErrNoRows {
+		return fmt.Errorf("no template named %q", name)
+	}
+	if err != nil {
+		return fmt.Errorf("error fetching template: %w", err)
+	}
+
+	if _, err := tx.ExecContext(ctx, "DELETE FROM template_tags WHERE template_id = ?", id); err != nil {
+		return fmt.Errorf("error deleting template tags: %w", err)
+	}
+	if _, err := tx.ExecContext(ctx, "DELETE FROM templates WHERE id = ?", id); err != nil {
+		return fmt.Errorf("error deleting template: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("error committing transaction: %w", err)
+	}
+	return nil
+}
+
+func fetchTagsForTemplate(ctx context.Context, tx querier, templateID int) ([]string, error) {
+	rows, err := tx.QueryContext(ctx, "SELECT t.name FROM tags t INNER JOIN template_tags tt ON t.id = tt.tag_id "+
+		"WHERE tt.template_id = ? ORDER BY t.name", templateID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var tags []string
+	for rows.Next() {
+		var tag string
+		if err := rows.Scan(&tag); err != nil {
+			return nil, err
+		}
+		tags = append(tags, tag)
+	}
+	return tags, rows.Err()
+}
+
+// mergeTags returns tags followed by those of extra it does not contain.
+func mergeTags(tags, extra []string) []string {
+	merged := append([]string(nil), tags...)
+	for _, tag := range extra {
+		if !slices.Contains(merged, tag) {
+			merged = append(merged, tag)
+		}
+	}
+	return merged
+}
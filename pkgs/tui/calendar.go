@@ -0,0 +1,207 @@
+package tui
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+
+	"go-time/pkgs/entry"
+	"go-time/pkgs/util"
+)
+
+const (
+	// slotLength is the time covered by a row of the calendar grid. Rows
+	// cover an hour instead when the grid would not fit the window.
+	slotLength = 30 * time.Minute
+
+	// firstHour and lastHour bound the grid unless entries of the week fall
+	// outside of them.
+	firstHour = 8
+	lastHour  = 18
+
+	timeLabelWidth = 6
+)
+
+// weekStart returns midnight of the Monday of the week containing day.
+func weekStart(day time.Time) time.Time {
+	day = util.StartOfDay(day)
+	return day.AddDate(0, 0, -(int(day.Weekday())+6)%7)
+}
+
+// dayEntries returns the shown entries overlapping day, ordered by start
+// time.
+func (m model) dayEntries(day time.Time) []entry.Entry {
+	end := day.AddDate(0, 0, 1)
+	var entries []entry.Entry
+	for _, e := range m.entries {
+		if e.StartTime.Before(end) && e.EndTime.After(day) {
+			entries = append(entries, e)
+		}
+	}
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].StartTime.Before(entries[j].StartTime)
+	})
+	return entries
+}
+
+// selectedBlock returns the entry selected on the calendar.
+func (m model) selectedBlock() (entry.Entry, bool) {
+	entries := m.dayEntries(m.calendarDay)
+	if m.calendarIndex < 0 || m.calendarIndex >= len(entries) {
+		return entry.Entry{}, false
+	}
+	return entries[m.calendarIndex], true
+}
+
+// updateCalendar handles the keys of the calendar tab. h and l move across
+// days, [ and ] across weeks, j and k between the entries of the selected
+// day, and x splits the selected entry. It reports whether msg was handled.
+func (m *model) updateCalendar(msg tea.KeyMsg) (tea.Cmd, bool) {
+	switch {
+	case key.Matches(msg, m.keymap.left):
+		m.moveCalendar(-1)
+	case key.Matches(msg, m.keymap.right):
+		m.moveCalendar(1)
+	case key.Matches(msg, m.keymap.prevWeek):
+		m.moveCalendar(-7)
+	case key.Matches(msg, m.keymap.nextWeek):
+		m.moveCalendar(7)
+	case key.Matches(msg, m.keymap.up):
+		if m.calendarIndex > 0 {
+			m.calendarIndex--
+		}
+	case key.Matches(msg, m.keymap.down):
+		if m.calendarIndex < len(m.dayEntries(m.calendarDay))-1 {
+			m.calendarIndex++
+		}
+	case key.Matches(msg, m.keymap.add), key.Matches(msg, m.keymap.edit):
+		if e, ok := m.selectedBlock(); ok {
+			return loadEntryForm(m.db, e.ID, m.tagNames()), true
+		}
+	case key.Matches(msg, m.keymap.split):
+		if e, ok := m.selectedBlock(); ok {
+			return m.openSplit(e), true
+		}
+	default:
+		return nil, false
+	}
+	return nil, true
+}
+
+func (m *model) moveCalendar(days int) {
+	m.calendarDay = util.StartOfDay(m.calendarDay.AddDate(0, 0, days).Add(12 * time.Hour))
+	m.calendarIndex = 0
+}
+
+func (m model) calendarView() string {
+	view := m.topBarView()
+
+	monday := weekStart(m.calendarDay)
+	days := make([]time.Time, 7)
+	for i := range days {
+		days[i] = monday.AddDate(0, 0, i)
+	}
+	dayEntries := make([][]entry.Entry, len(days))
+	for i, day := range days {
+		dayEntries[i] = m.dayEntries(day)
+	}
+
+	// Widen the grid to the hours of the week's entries.
+	first, last := firstHour, lastHour
+	for i, day := range days {
+		for _, e := range dayEntries[i] {
+			start := e.StartTime.In(util.Location())
+			end := e.EndTime.In(util.Location())
+			if start.Before(day) {
+				first = 0
+			} else {
+				first = min(first, start.Hour())
+			}
+			if !end.Before(day.AddDate(0, 0, 1)) {
+				last = 24
+			} else {
+				last = max(last, end.Hour()+1)
+			}
+		}
+	}
+
+	width, height := m.width, m.height
+	if width == 0 {
+		width, height = defaultWidth, defaultHeight
+	}
+	slot := slotLength
+	if (last-first)*int(time.Hour/slot) > height-chromeHeight {
+		slot = time.Hour
+	}
+	columnWidth := max((width-timeLabelWidth)/7-1, 6)
+
+	view += "Week of " + monday.Format("Mon 2 Jan 2006") + "\n"
+	header := strings.Repeat(" ", timeLabelWidth)
+	for _, day := range days {
+		label := fit(day.Format("Mon 02"), columnWidth)
+		if day.Equal(m.calendarDay) {
+			label = m.theme.tab.Render(label)
+		}
+		header += label + " "
+	}
+	view += header + "\n"
+
+	selected, hasSelected := m.selectedBlock()
+	for h := first * 60; h < last*60; h += int(slot / time.Minute) {
+		line := strings.Repeat(" ", timeLabelWidth)
+		if h%60 == 0 {
+			line = fit(time.Date(0, 1, 1, h/60, 0, 0, 0, time.UTC).Format("15:04"), timeLabelWidth)
+		}
+		for i, day := range days {
+			slotStart := time.Date(day.Year(), day.Month(), day.Day(), h/60, h%60, 0, 0, day.Location())
+			line += m.slotView(day, dayEntries[i], slotStart, slotStart.Add(slot), columnWidth, selected, hasSelected) + " "
+		}
+		view += line + "\n"
+	}
+
+	view += m.helpView()
+	return view
+}
+
+// slotView draws a cell of the grid from the entries of its day. Entries are
+// shown as blocks labelled with their name in their first slot and a bar
+// below it, slots with more than one entry are marked as overlaps and empty
+// slots show gaps.
+func (m model) slotView(day time.Time, entries []entry.Entry, start, end time.Time, width int, selected entry.Entry, hasSelected bool) string {
+	var in []entry.Entry
+	for _, e := range entries {
+		if e.StartTime.Before(end) && e.EndTime.After(start) {
+			in = append(in, e)
+		}
+	}
+
+	switch len(in) {
+	case 0:
+		return strings.Repeat(" ", width)
+	case 1:
+		e := in[0]
+		label := "┃"
+		if !e.StartTime.Before(start) || (e.StartTime.Before(day) && start.Equal(day)) {
+			label = e.Name
+		}
+		style := m.theme.block
+		if hasSelected && e.ID == selected.ID && day.Equal(m.calendarDay) {
+			style = m.theme.selected
+		}
+		return style.Render(fit(label, width))
+	default:
+		return m.theme.overlap.Render(fit("overlap", width))
+	}
+}
+
+// fit truncates or pads s to exactly width characters.
+func fit(s string, width int) string {
+	r := []rune(s)
+	if len(r) > width {
+		return string(r[:width-1]) + "…"
+	}
+	return s + strings.Repeat(" ", width-len(r))
+}
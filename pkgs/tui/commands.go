@@ -0,0 +1,326 @@
+package tui
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/huh"
+
+	"go-time/pkgs/entry"
+	"go-time/pkgs/pomodoro"
+	"go-time/pkgs/tag"
+	"go-time/pkgs/timer"
+	"go-time/pkgs/util"
+)
+
+// refreshInterval is how often the data is reloaded to pick up changes made
+// outside the TUI, such as timers started from the command line.
+const refreshInterval = 30 * time.Second
+
+// dataMsg carries the records loaded by loadData.
+type dataMsg struct {
+	entries   []entry.Entry
+	timers    []timer.Timer
+	tags      []tag.Tag
+	templates []timer.Template
+	err       error
+}
+
+// mutationMsg reports the outcome of a change to the database.
+type mutationMsg struct {
+	status string
+	err    error
+
+	// retry is the form to reopen when saving a form failed, together with
+	// the kind and ID of the record it edits.
+	retry     *huh.Form
+	retryKind string
+	retryID   int
+
+	// unmark lists the entries to unmark once the change is made.
+	unmark []int
+}
+
+// editFormMsg carries a form prefilled with the record to edit.
+type editFormMsg struct {
+	form *huh.Form
+	kind string
+	id   int
+	err  error
+}
+
+type refreshMsg struct{}
+
+// heartbeatMsg carries the idle periods found by a heartbeat.
+type heartbeatMsg struct {
+	idle []timer.Idle
+	err  error
+}
+
+// idleMsg asks what to do with the idle time of a timer.
+type idleMsg struct {
+	prompt idlePrompt
+}
+
+func loadData(db *sql.DB) tea.Cmd {
+	return func() tea.Msg {
+		ctx := context.Background()
+		var msg dataMsg
+		if msg.entries, msg.err = entry.ReadEntries(ctx, db); msg.err != nil {
+			return msg
+		}
+		if msg.timers, msg.err = timer.ReadTimers(ctx, db); msg.err != nil {
+			return msg
+		}
+		if msg.tags, msg.err = tag.GetTags(ctx, db); msg.err != nil {
+			return msg
+		}
+		if msg.templates, msg.err = timer.GetTemplates(ctx, db); msg.err != nil {
+			return msg
+		}
+
+		entryTags, err := tag.GetTagsByEntry(ctx, db)
+		if err != nil {
+			msg.err = err
+			return msg
+		}
+		for i := range msg.entries {
+			msg.entries[i].Tags = entryTags[msg.entries[i].ID]
+		}
+
+		timerTags, err := tag.GetTagsByTimer(ctx, db)
+		if err != nil {
+			msg.err = err
+			return msg
+		}
+		laps, err := timer.GetLapsByTimer(ctx, db)
+		if err != nil {
+			msg.err = err
+			return msg
+		}
+		for i := range msg.timers {
+			msg.timers[i].Tags = tag.Names(timerTags[msg.timers[i].ID])
+			msg.timers[i].Laps = laps[msg.timers[i].ID]
+		}
+		return msg
+	}
+}
+
+func refreshTick() tea.Cmd {
+	return tea.Tick(refreshInterval, func(time.Time) tea.Msg {
+		return refreshMsg{}
+	})
+}
+
+func saveEntry(db *sql.DB, id int, result entry.FormResult, tags []string) tea.Cmd {
+	return func() tea.Msg {
+		if err := entry.SaveForm(context.Background(), db, id, result); err != nil {
+			return mutationMsg{err: err, retry: entry.ResultForm(result, tags), retryKind: "entries", retryID: id}
+		}
+		if id != 0 {
+			return mutationMsg{status: "Entry updated: " + result.Name}
+		}
+		return mutationMsg{status: "Entry saved successfully for: " + result.Name}
+	}
+}
+
+func saveTimer(db *sql.DB, id int, result timer.FormResult, tags []string, templates []timer.Template) tea.Cmd {
+	return func() tea.Msg {
+		ctx := context.Background()
+		if id != 0 {
+			if err := timer.EditTimer(ctx, db, id, result.Name, result.StartTime, result.Tags); err != nil {
+				t := timer.Timer{ID: id, Name: result.Name, StartTime: result.StartTime, Tags: result.Tags}
+				return mutationMsg{err: err, retry: timer.EditForm(t, tags), retryKind: "timers", retryID: id}
+			}
+			return mutationMsg{status: "Timer updated: " + result.Name}
+		}
+
+		if err := timer.Start(ctx, db, result.Name, result.Description, result.Project, result.Tags); err != nil {
+			return mutationMsg{err: err, retry: timer.Form(tags, templates), retryKind: "timers"}
+		}
+		return mutationMsg{status: "Timer started for task: " + result.Name}
+	}
+}
+
+func saveTag(db *sql.DB, id int, name string) tea.Cmd {
+	return func() tea.Msg {
+		ctx := context.Background()
+		var err error
+		if id != 0 {
+			err = tag.EditTag(ctx, db, id, name)
+		} else {
+			err = tag.CreateTag(ctx, db, name)
+		}
+		if err != nil {
+			return mutationMsg{err: err, retry: tag.EditForm(tag.Tag{ID: id, Name: name}), retryKind: "tags", retryID: id}
+		}
+		return mutationMsg{status: "Tag saved: " + name}
+	}
+}
+
+// restartEntry starts a new timer with the name and tags of an entry.
+func restartEntry(db *sql.DB, id int) tea.Cmd {
+	return func() tea.Msg {
+		ctx := context.Background()
+		e, err := entry.GetEntry(ctx, db, id)
+		if err != nil {
+			return mutationMsg{err: err}
+		}
+		if err := timer.CreateTimer(ctx, db, e.Name, tag.Names(e.Tags)); err != nil {
+			return mutationMsg{err: err}
+		}
+		return mutationMsg{status: "Timer started for task: " + e.Name}
+	}
+}
+
+// stopTimer stops t, or asks what to do with its idle time first if it was
+// idle for at least threshold.
+func stopTimer(db *sql.DB, t timer.Timer, threshold time.Duration) tea.Cmd {
+	return func() tea.Msg {
+		idle, err := timer.IdlePeriods(context.Background(), db, t.Name, time.Now(), threshold)
+		if err != nil {
+			return mutationMsg{err: err}
+		}
+		if len(idle) > 0 {
+			return idleMsg{idlePrompt{timer: t, idle: idle, stop: true}}
+		}
+		return stopTimerWith(db, t, threshold, timer.KeepIdle)()
+	}
+}
+
+func stopTimerWith(db *sql.DB, t timer.Timer, threshold time.Duration, action timer.IdleAction) tea.Cmd {
+	return func() tea.Msg {
+		entryIDs, err := timer.Stop(context.Background(), db, t.Name, timer.StopOptions{IdleThreshold: threshold, Idle: action})
+		if err != nil {
+			return mutationMsg{err: err}
+		}
+		if len(entryIDs) == 0 {
+			return mutationMsg{status: "Timer stopped for task: " + t.Name + ", no time left to record"}
+		}
+		if action != timer.KeepIdle {
+			return mutationMsg{status: fmt.Sprintf("Timer stopped for task: %s, idle time %s", t.Name, pastTense[action])}
+		}
+		return mutationMsg{status: fmt.Sprintf("Timer stopped for task: %s, created entry %d (%s)",
+			t.Name, entryIDs[0], util.FormatDuration(time.Since(t.StartTime)))}
+	}
+}
+
+// stopTimerAt stops a forgotten timer at the time it really ended.
+func stopTimerAt(db *sql.DB, t timer.Timer, end time.Time) tea.Cmd {
+	return func() tea.Msg {
+		if _, err := timer.Stop(context.Background(), db, t.Name, timer.StopOptions{End: end}); err != nil {
+			return mutationMsg{err: err}
+		}
+		return mutationMsg{status: fmt.Sprintf("Timer stopped for task: %s at %s (%s)",
+			t.Name, util.FormatTime(end), util.FormatDuration(end.Sub(t.StartTime)))}
+	}
+}
+
+// resumeTimer resolves the idle time of t once there is activity again.
+func resumeTimer(db *sql.DB, t timer.Timer, action timer.IdleAction) tea.Cmd {
+	return func() tea.Msg {
+		if _, err := timer.Resume(context.Background(), db, t.Name, action); err != nil {
+			return mutationMsg{err: err}
+		}
+		return mutationMsg{status: fmt.Sprintf("Resumed timer for task: %s, idle time %s", t.Name, pastTense[action])}
+	}
+}
+
+var pastTense = map[timer.IdleAction]string{
+	timer.KeepIdle:    "kept",
+	timer.DiscardIdle: "discarded",
+	timer.SplitIdle:   "split off",
+}
+
+// heartbeat records activity for the running timers and reports the idle
+// time it found.
+func heartbeat(db *sql.DB, threshold time.Duration) tea.Cmd {
+	return func() tea.Msg {
+		idle, err := timer.Heartbeat(context.Background(), db, time.Now(), threshold)
+		return heartbeatMsg{idle: idle, err: err}
+	}
+}
+
+func addLap(db *sql.DB, t timer.Timer, note string) tea.Cmd {
+	return func() tea.Msg {
+		if _, err := timer.AddLap(context.Background(), db, t.Name, note); err != nil {
+			return mutationMsg{err: err}
+		}
+		return mutationMsg{status: fmt.Sprintf("Lap marked for task: %s (%s)", t.Name, util.FormatDuration(time.Since(t.StartTime)))}
+	}
+}
+
+// deleteRecord deletes the record of the given kind, described by label in
+// the status message.
+func deleteRecord(db *sql.DB, kind string, id int, label string) tea.Cmd {
+	return func() tea.Msg {
+		ctx := context.Background()
+		var err error
+		switch kind {
+		case "entries":
+			err = entry.DeleteEntry(ctx, db, id)
+		case "timers":
+			err = timer.DeleteTimer(ctx, db, id)
+		case "tags":
+			err = tag.DeleteTag(ctx, db, id)
+		}
+		if err != nil {
+			return mutationMsg{err: err}
+		}
+		return mutationMsg{status: "Deleted " + label}
+	}
+}
+
+func loadEntryForm(db *sql.DB, id int, tags []string) tea.Cmd {
+	return func() tea.Msg {
+		e, err := entry.GetEntry(context.Background(), db, id)
+		if err != nil {
+			return editFormMsg{err: err}
+		}
+		return editFormMsg{form: entry.EditForm(e, tags), kind: "entries", id: id}
+	}
+}
+
+func loadTimerForm(db *sql.DB, id int, tags []string) tea.Cmd {
+	return func() tea.Msg {
+		t, err := timer.GetTimer(context.Background(), db, id)
+		if err != nil {
+			return editFormMsg{err: err}
+		}
+		return editFormMsg{form: timer.EditForm(t, tags), kind: "timers", id: id}
+	}
+}
+
+// splitEntry splits e in two at at.
+func splitEntry(db *sql.DB, e entry.Entry, at time.Time) tea.Cmd {
+	return func() tea.Msg {
+		newID, err := entry.SplitEntry(context.Background(), db, e.ID, at)
+		if err != nil {
+			return mutationMsg{err: err}
+		}
+		return mutationMsg{status: fmt.Sprintf("Split entry %q at %s into entries %d and %d", e.Name, util.FormatTime(at), e.ID, newID)}
+	}
+}
+
+// mergeEntries merges the entries with the given IDs into the first one.
+func mergeEntries(db *sql.DB, ids []int) tea.Cmd {
+	return func() tea.Msg {
+		if err := entry.MergeEntries(context.Background(), db, ids...); err != nil {
+			return mutationMsg{err: err}
+		}
+		return mutationMsg{status: fmt.Sprintf("Merged %d entries into entry %d", len(ids), ids[0]), unmark: ids}
+	}
+}
+
+// recordPomodoro saves a work interval of the pomodoro session as an entry.
+func recordPomodoro(db *sql.DB, session pomodoro.Session, worked pomodoro.Interval) tea.Cmd {
+	return func() tea.Msg {
+		if err := session.Record(context.Background(), db, worked); err != nil {
+			return mutationMsg{err: err}
+		}
+		return mutationMsg{status: fmt.Sprintf("Recorded pomodoro %d for: %s", worked.Number, session.Name)}
+	}
+}
@@ -0,0 +1,102 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"go-time/pkgs/entry"
+	"go-time/pkgs/report"
+	"go-time/pkgs/tag"
+	"go-time/pkgs/util"
+)
+
+// topTags is the number of tags listed on the dashboard.
+const topTags = 5
+
+// trackedEntries returns the shown entries together with the running timers
+// as entries ending at now, so that totals include time still being tracked.
+func (m model) trackedEntries(now time.Time) []entry.Entry {
+	entries := append([]entry.Entry(nil), m.entries...)
+	for _, t := range m.timers {
+		e := entry.Entry{Name: t.Name, StartTime: t.StartTime, EndTime: now}
+		for _, name := range t.Tags {
+			e.Tags = append(e.Tags, tag.Tag{Name: name})
+		}
+		entries = append(entries, e)
+	}
+	return entries
+}
+
+func (m model) dashboardView() string {
+	view := m.topBarView()
+
+	now := time.Now()
+	entries := m.trackedEntries(now)
+
+	today := util.StartOfDay(now)
+	monday := today.AddDate(0, 0, -(int(today.Weekday())+6)%7)
+	week := report.DailyTotals(entries, monday, monday.AddDate(0, 0, 6))
+	todayTotal := report.DailyTotals(entries, today, today)
+
+	view += fmt.Sprintf("Today: %s   This week: %s\n",
+		m.theme.bold.Render(util.FormatDuration(report.Total(todayTotal))),
+		m.theme.bold.Render(util.FormatDuration(report.Total(week))))
+
+	view += m.theme.heading.Render("This week") + "\n"
+	var longest time.Duration
+	for _, day := range week {
+		longest = max(longest, day.Duration)
+	}
+	for _, day := range week {
+		label := day.Day.Format("Mon 02")
+		if day.Day.Equal(today) {
+			label = m.theme.bold.Render(label)
+		}
+		view += fmt.Sprintf("%s %s %s\n", label, m.bar(day.Duration, longest), util.FormatDuration(day.Duration))
+	}
+
+	view += m.theme.heading.Render("Top tags this week") + "\n"
+	tags := report.TagTotals(entries, monday, monday.AddDate(0, 0, 7))
+	if len(tags) == 0 {
+		view += m.theme.muted.Render("No tagged time this week") + "\n"
+	}
+	width := 0
+	for _, t := range tags {
+		width = max(width, len(t.Tag))
+	}
+	for i, t := range tags {
+		if i == topTags {
+			break
+		}
+		view += fmt.Sprintf("%-*s %s %s\n", width, t.Tag, m.bar(t.Duration, tags[0].Duration), util.FormatDuration(t.Duration))
+	}
+
+	view += m.theme.heading.Render("Running timers") + "\n"
+	if len(m.timers) == 0 {
+		view += m.theme.muted.Render("No running timers") + "\n"
+	}
+	for _, t := range m.timers {
+		elapsed := m.theme.running.Render(m.watches[t.ID].View())
+		view += fmt.Sprintf("%s  %s %s\n", elapsed, t.Name, m.theme.muted.Render(strings.Join(t.Tags, ", ")))
+	}
+
+	view += m.helpView()
+	return view
+}
+
+// bar draws d as a horizontal bar, scaled so that longest fills the space
+// left on the line for the label and the duration.
+func (m model) bar(d, longest time.Duration) string {
+	width := m.width
+	if width == 0 {
+		width = defaultWidth
+	}
+	width = max(width-30, 10)
+
+	n := 0
+	if longest > 0 {
+		n = int(int64(width) * int64(d) / int64(longest))
+	}
+	return m.theme.bar.Render(strings.Repeat("█", n)) + strings.Repeat(" ", width-n)
+}
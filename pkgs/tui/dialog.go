@@ -0,0 +1,158 @@
+package tui
+
+import (
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"go-time/pkgs/timer"
+)
+
+// toastDuration is how long a status message stays on screen.
+const toastDuration = 4 * time.Second
+
+// toast is a status message shown below the current view until it expires.
+type toast struct {
+	text  string
+	isErr bool
+	id    int
+}
+
+type toastExpiredMsg struct {
+	id int
+}
+
+// confirmation is a pending destructive action that only runs once the user
+// confirms it.
+type confirmation struct {
+	prompt string
+	action tea.Cmd
+}
+
+// notify shows text as a toast and returns a command that hides it again
+// after toastDuration, unless another toast replaced it in the meantime.
+func (m *model) notify(text string, isErr bool) tea.Cmd {
+	m.toast = toast{text: text, isErr: isErr, id: m.toast.id + 1}
+	id := m.toast.id
+	return tea.Tick(toastDuration, func(time.Time) tea.Msg {
+		return toastExpiredMsg{id: id}
+	})
+}
+
+func (m *model) notifyErr(err error) tea.Cmd {
+	return m.notify("Error: "+err.Error(), true)
+}
+
+// confirm asks the user to confirm prompt before running action.
+func (m *model) confirm(prompt string, action tea.Cmd) {
+	m.confirmation = &confirmation{prompt: prompt, action: action}
+}
+
+// updateConfirm handles the keys of the confirm dialog: y or enter runs the
+// action, n or esc cancels it. Other messages are ignored while the dialog
+// is open.
+func (m *model) updateConfirm(msg tea.Msg) tea.Cmd {
+	key, ok := msg.(tea.KeyMsg)
+	if !ok {
+		return nil
+	}
+	switch key.String() {
+	case "y", "Y", "enter":
+		action := m.confirmation.action
+		m.confirmation = nil
+		return action
+	case "n", "N", "esc":
+		m.confirmation = nil
+		return m.notify("Cancelled", false)
+	}
+	return nil
+}
+
+func (m model) confirmView() string {
+	return m.dialogView(m.confirmation.prompt + "\n\n" + m.theme.helpKey.Render("y") + " confirm  " + m.theme.helpKey.Render("n") + " cancel")
+}
+
+// dialogView draws text in a box in the middle of the screen.
+func (m model) dialogView(text string) string {
+	dialog := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(m.theme.overlap.GetBackground()).
+		Padding(1, 3).
+		Render(text)
+
+	width, height := m.width, m.height
+	if width == 0 {
+		width, height = defaultWidth, defaultHeight
+	}
+	return lipgloss.Place(width, height, lipgloss.Center, lipgloss.Center, dialog)
+}
+
+// idlePrompt asks what to do with the idle time of a timer, either when it
+// is stopped or when there is activity again while it runs.
+type idlePrompt struct {
+	timer timer.Timer
+	idle  []timer.Idle
+	stop  bool
+}
+
+// promptIdle queues p, replacing a queued prompt for the same timer.
+func (m *model) promptIdle(p idlePrompt) {
+	for i := range m.idlePrompts {
+		if m.idlePrompts[i].timer.ID == p.timer.ID {
+			m.idlePrompts[i] = p
+			return
+		}
+	}
+	m.idlePrompts = append(m.idlePrompts, p)
+}
+
+// updateIdle handles the keys of the first queued idle prompt: d, k or s
+// discard, keep or split off the idle time. Esc cancels stopping the timer,
+// or leaves the idle time to be resolved when the timer is stopped.
+func (m *model) updateIdle(msg tea.Msg) tea.Cmd {
+	key, ok := msg.(tea.KeyMsg)
+	if !ok {
+		return nil
+	}
+	p := m.idlePrompts[0]
+
+	var action timer.IdleAction
+	switch key.String() {
+	case "d":
+		action = timer.DiscardIdle
+	case "k":
+		action = timer.KeepIdle
+	case "s":
+		action = timer.SplitIdle
+	case "esc":
+		m.idlePrompts = m.idlePrompts[1:]
+		if p.stop {
+			return m.notify("Cancelled", false)
+		}
+		return nil
+	default:
+		return nil
+	}
+
+	m.idlePrompts = m.idlePrompts[1:]
+	if p.stop {
+		return stopTimerWith(m.db, p.timer, m.idleThreshold, action)
+	}
+	return resumeTimer(m.db, p.timer, action)
+}
+
+func (m model) idleView() string {
+	p := m.idlePrompts[0]
+	later := "later"
+	if p.stop {
+		later = "cancel"
+	}
+	keys := []string{"d", "discard", "k", "keep", "s", "split", "esc", later}
+	var help []string
+	for i := 0; i < len(keys); i += 2 {
+		help = append(help, m.theme.helpKey.Render(keys[i])+" "+keys[i+1])
+	}
+	return m.dialogView(timer.IdleSummary(p.idle) + "\n\n" + strings.Join(help, "  "))
+}
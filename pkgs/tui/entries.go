@@ -0,0 +1,69 @@
+package tui
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+
+	"go-time/pkgs/entry"
+)
+
+// updateEntries handles the keys of the entries tab that split, mark and
+// merge entries. It reports false for keys that are not specific to the tab.
+func (m *model) updateEntries(msg tea.KeyMsg) (tea.Cmd, bool) {
+	switch {
+	case key.Matches(msg, m.keymap.split):
+		if len(m.entries) > 0 {
+			return m.openSplit(m.entries[m.entriesTable.Cursor()]), true
+		}
+		return nil, true
+
+	case key.Matches(msg, m.keymap.mark):
+		if len(m.entries) > 0 {
+			id := m.entries[m.entriesTable.Cursor()].ID
+			if m.marked[id] {
+				delete(m.marked, id)
+			} else {
+				m.marked[id] = true
+			}
+			m.refreshTables(m.selection())
+			m.moveCursor(1)
+		}
+		return nil, true
+
+	case key.Matches(msg, m.keymap.merge):
+		marked := m.markedEntries()
+		if len(marked) < 2 {
+			return m.notify(fmt.Sprintf("Mark at least two entries with %s to merge them", m.keymap.mark.Help().Key), true), true
+		}
+		ids := make([]int, len(marked))
+		for i, e := range marked {
+			ids[i] = e.ID
+		}
+		m.confirm(fmt.Sprintf("Merge %d entries into entry %d %q?", len(ids), ids[0], marked[0].Name),
+			mergeEntries(m.db, ids))
+		return nil, true
+	}
+	return nil, false
+}
+
+// openSplit opens the form asking where to split e.
+func (m *model) openSplit(e entry.Entry) tea.Cmd {
+	m.form = entry.SplitForm(e)
+	return m.openForm("split", e.ID)
+}
+
+// markedEntries returns the marked entries that are still loaded, earliest
+// first, so that merging keeps the first of them.
+func (m *model) markedEntries() []entry.Entry {
+	var marked []entry.Entry
+	for _, e := range m.loaded.entries {
+		if m.marked[e.ID] {
+			marked = append(marked, e)
+		}
+	}
+	slices.SortStableFunc(marked, func(a, b entry.Entry) int { return a.StartTime.Compare(b.StartTime) })
+	return marked
+}
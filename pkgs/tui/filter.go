@@ -0,0 +1,127 @@
+package tui
+
+import (
+	"strings"
+	"time"
+
+	"go-time/pkgs/entry"
+	"go-time/pkgs/tag"
+	"go-time/pkgs/timer"
+	"go-time/pkgs/util"
+)
+
+// filter narrows the records shown in the TUI. Each term must fuzzy match
+// the name or one of the tags of a record. Terms starting with @ select a
+// date range instead: @today, @yesterday, @week (this week, from Monday),
+// @month or a single day as @YYYY-MM-DD.
+type filter struct {
+	terms    []string
+	from, to time.Time
+	err      string
+}
+
+func parseFilter(s string, now time.Time) filter {
+	var f filter
+	for _, term := range strings.Fields(strings.ToLower(s)) {
+		if !strings.HasPrefix(term, "@") {
+			f.terms = append(f.terms, term)
+			continue
+		}
+
+		today := util.StartOfDay(now)
+		switch preset := term[1:]; preset {
+		case "today":
+			f.from, f.to = today, today.AddDate(0, 0, 1)
+		case "yesterday":
+			f.from, f.to = today.AddDate(0, 0, -1), today
+		case "week":
+			monday := today.AddDate(0, 0, -(int(today.Weekday())+6)%7)
+			f.from, f.to = monday, monday.AddDate(0, 0, 7)
+		case "month":
+			f.from = today.AddDate(0, 0, 1-today.Day())
+			f.to = f.from.AddDate(0, 1, 0)
+		default:
+			day, err := time.ParseInLocation("2006-01-02", preset, util.Location())
+			if err != nil {
+				f.err = "unknown date " + term
+				continue
+			}
+			f.from, f.to = day, day.AddDate(0, 0, 1)
+		}
+	}
+	return f
+}
+
+func (f filter) active() bool {
+	return len(f.terms) > 0 || !f.from.IsZero()
+}
+
+// matches reports whether every term fuzzy matches name or one of tags.
+func (f filter) matches(name string, tags []string) bool {
+	for _, term := range f.terms {
+		if fuzzyMatch(term, name) {
+			continue
+		}
+		found := false
+		for _, t := range tags {
+			if fuzzyMatch(term, t) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// during reports whether the range from start to end overlaps the date range
+// of the filter, if it has one.
+func (f filter) during(start, end time.Time) bool {
+	return f.from.IsZero() || (start.Before(f.to) && end.After(f.from))
+}
+
+func (f filter) entries(entries []entry.Entry) []entry.Entry {
+	var result []entry.Entry
+	for _, e := range entries {
+		if f.matches(e.Name, tag.Names(e.Tags)) && f.during(e.StartTime, e.EndTime) {
+			result = append(result, e)
+		}
+	}
+	return result
+}
+
+func (f filter) timers(timers []timer.Timer, now time.Time) []timer.Timer {
+	var result []timer.Timer
+	for _, t := range timers {
+		if f.matches(t.Name, t.Tags) && f.during(t.StartTime, now) {
+			result = append(result, t)
+		}
+	}
+	return result
+}
+
+func (f filter) tags(tags []tag.Tag) []tag.Tag {
+	var result []tag.Tag
+	for _, t := range tags {
+		if f.matches(t.Name, nil) {
+			result = append(result, t)
+		}
+	}
+	return result
+}
+
+// fuzzyMatch reports whether the characters of pattern appear in s in order,
+// ignoring case.
+func fuzzyMatch(pattern, s string) bool {
+	s = strings.ToLower(s)
+	for _, r := range pattern {
+		i := strings.IndexRune(s, r)
+		if i < 0 {
+			return false
+		}
+		s = s[i+len(string(r)):]
+	}
+	return true
+}
@@ -2,10 +2,17 @@ package tui
 
 import (
 	"database/sql"
+	"fmt"
 	"github.com/charmbracelet/bubbles/help"
 	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
+	"go-time/pkgs/config"
+	"go-time/pkgs/pomodoro"
+	"go-time/pkgs/stopwatch"
 	"go-time/pkgs/tag"
+	"go-time/pkgs/util"
+	"time"
 )
 
 type keymap struct {
@@ -19,44 +26,134 @@ type keymap struct {
 	edit   key.Binding
 	delete key.Binding
 	quit   key.Binding
+
+	pageUp   key.Binding
+	pageDown key.Binding
+	sort     key.Binding
+	reverse  key.Binding
+	filter   key.Binding
+	nextTab  key.Binding
+	prevTab  key.Binding
+	nextWeek key.Binding
+	prevWeek key.Binding
+	skip     key.Binding
+	lap      key.Binding
+	split    key.Binding
+	mark     key.Binding
+	merge    key.Binding
+}
+
+// action is a TUI command that can be bound to keys in the config file.
+type action struct {
+	name    string
+	keys    []string
+	help    string
+	binding func(*keymap) *key.Binding
 }
 
-func initialModel(db *sql.DB) *model {
-	keymap := keymap{
-		start: key.NewBinding(key.WithKeys("s"), key.WithHelp("s", "start timer")),
-		stop:  key.NewBinding(key.WithKeys("t"), key.WithHelp("t", "stop timer")),
-		up:    key.NewBinding(key.WithKeys("k", "up"), key.WithHelp("k", "up")),
-		down:  key.NewBinding(key.WithKeys("j", "down"), key.WithHelp("j", "down")),
-		left:  key.NewBinding(key.WithKeys("h", "left"), key.WithHelp("h", "left")),
-		right: key.NewBinding(key.WithKeys("l", "right"), key.WithHelp("l", "right")),
-
-		quit:   key.NewBinding(key.WithKeys("q", "Esc"), key.WithHelp("q", "quit")),
-		edit:   key.NewBinding(key.WithKeys("e"), key.WithHelp("e", "edit")),
-		add:    key.NewBinding(key.WithKeys("enter", " "), key.WithHelp("enter", "select")),
-		delete: key.NewBinding(key.WithKeys("d"), key.WithHelp("d", "delete")),
+var actions = []action{
+	{"start", []string{"s"}, "start timer", func(k *keymap) *key.Binding { return &k.start }},
+	{"stop", []string{"t"}, "stop timer", func(k *keymap) *key.Binding { return &k.stop }},
+	{"up", []string{"k", "up"}, "up", func(k *keymap) *key.Binding { return &k.up }},
+	{"down", []string{"j", "down"}, "down", func(k *keymap) *key.Binding { return &k.down }},
+	{"left", []string{"h", "left"}, "left", func(k *keymap) *key.Binding { return &k.left }},
+	{"right", []string{"l", "right"}, "right", func(k *keymap) *key.Binding { return &k.right }},
+	{"add", []string{"enter", " "}, "select", func(k *keymap) *key.Binding { return &k.add }},
+	{"edit", []string{"e"}, "edit", func(k *keymap) *key.Binding { return &k.edit }},
+	{"delete", []string{"d"}, "delete", func(k *keymap) *key.Binding { return &k.delete }},
+	{"quit", []string{"q", "esc"}, "quit", func(k *keymap) *key.Binding { return &k.quit }},
+	{"page_up", []string{"pgup", "b"}, "page up", func(k *keymap) *key.Binding { return &k.pageUp }},
+	{"page_down", []string{"pgdown", "f"}, "page down", func(k *keymap) *key.Binding { return &k.pageDown }},
+	{"sort", []string{"o"}, "sort column", func(k *keymap) *key.Binding { return &k.sort }},
+	{"reverse", []string{"O"}, "reverse sort", func(k *keymap) *key.Binding { return &k.reverse }},
+	{"filter", []string{"/"}, "filter", func(k *keymap) *key.Binding { return &k.filter }},
+	{"next_tab", []string{"tab"}, "next tab", func(k *keymap) *key.Binding { return &k.nextTab }},
+	{"prev_tab", []string{"shift+tab"}, "previous tab", func(k *keymap) *key.Binding { return &k.prevTab }},
+	{"next_week", []string{"]"}, "next week", func(k *keymap) *key.Binding { return &k.nextWeek }},
+	{"prev_week", []string{"["}, "previous week", func(k *keymap) *key.Binding { return &k.prevWeek }},
+	{"skip", []string{"n"}, "next phase", func(k *keymap) *key.Binding { return &k.skip }},
+	{"lap", []string{"p"}, "lap", func(k *keymap) *key.Binding { return &k.lap }},
+	{"split", []string{"x"}, "split", func(k *keymap) *key.Binding { return &k.split }},
+	{"mark", []string{"m"}, "mark", func(k *keymap) *key.Binding { return &k.mark }},
+	{"merge", []string{"M"}, "merge marked", func(k *keymap) *key.Binding { return &k.merge }},
+}
+
+// newKeymap binds every action to its default keys, or to the keys given
+// for it in bindings. A key bound to more than one action is an error.
+func newKeymap(bindings map[string][]string) (keymap, error) {
+	known := make(map[string]bool, len(actions))
+	for _, a := range actions {
+		known[a.name] = true
+	}
+	for name := range bindings {
+		if !known[name] {
+			return keymap{}, fmt.Errorf("unknown action %q in key bindings", name)
+		}
+	}
+
+	var k keymap
+	bound := make(map[string]string)
+	for _, a := range actions {
+		keys := a.keys
+		if custom, ok := bindings[a.name]; ok {
+			if len(custom) == 0 {
+				return keymap{}, fmt.Errorf("no keys bound to action %q", a.name)
+			}
+			keys = custom
+		}
+		for _, k := range keys {
+			if other, ok := bound[k]; ok {
+				return keymap{}, fmt.Errorf("key %q is bound to both %s and %s", k, other, a.name)
+			}
+			bound[k] = a.name
+		}
+		*a.binding(&k) = key.NewBinding(key.WithKeys(keys...), key.WithHelp(keys[0], a.help))
+	}
+	return k, nil
+}
+
+func initialModel(db *sql.DB, settings config.AppConfig) (*model, error) {
+	keymap, err := newKeymap(settings.Keys)
+	if err != nil {
+		return nil, err
+	}
+	theme, err := newTheme(settings.Theme, settings.Colors)
+	if err != nil {
+		return nil, err
 	}
+
+	filterInput := textinput.New()
+	filterInput.Prompt = "/"
+	filterInput.Placeholder = "name, tag, @today, @week, @YYYY-MM-DD"
+
+	h := help.New()
+	h.Styles.ShortKey = theme.helpKey
+	h.Styles.ShortDesc = theme.muted
+	h.Styles.ShortSeparator = theme.muted
+
 	return &model{
 		db:          db,
 		currentView: "timers",
 		keymap:      keymap,
-		help:        help.New(),
+		theme:       theme,
+		help:        h,
 		form:        tag.Form(),
 		formActive:  false,
-	}
+
+		entriesTable: newTable(theme),
+		timersTable:  newTable(theme),
+		tagsTable:    newTable(theme),
+		filterInput:  filterInput,
+		calendarDay:  util.StartOfDay(time.Now()),
+		marked:       make(map[int]bool),
+
+		pomodoroSettings: pomodoro.SettingsFrom(settings),
+		idleThreshold:    time.Duration(settings.IdleThreshold) * time.Minute,
+		maxTimer:         time.Duration(settings.MaxTimerHours) * time.Hour,
+	}, nil
 }
 
 func (m *model) Init() tea.Cmd {
-	err := m.updateTimers()
-	if err != nil {
-		return tea.Quit
-	}
-	err = m.updateEntries()
-	if err != nil {
-		return tea.Quit
-	}
-	err = m.updateTags()
-	if err != nil {
-		return tea.Quit
-	}
-	return nil
+	m.lastBeat = time.Now()
+	return tea.Batch(m.load(), refreshTick(), stopwatch.Tick(tickInterval), heartbeat(m.db, m.idleThreshold))
 }
@@ -0,0 +1,103 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+
+	"go-time/pkgs/pomodoro"
+	"go-time/pkgs/timer"
+	"go-time/pkgs/util"
+)
+
+// updatePomodoro handles the keys of the pomodoro tab. It reports false for
+// keys that are not specific to the tab.
+func (m *model) updatePomodoro(msg tea.KeyMsg) (tea.Cmd, bool) {
+	switch {
+	case key.Matches(msg, m.keymap.add), key.Matches(msg, m.keymap.start):
+		if m.pomodoro != nil {
+			return nil, true
+		}
+		m.form = m.timerForm()
+		return m.openForm("pomodoro", 0), true
+
+	case key.Matches(msg, m.keymap.stop):
+		if m.pomodoro == nil {
+			return nil, true
+		}
+		return tea.Batch(m.stopPomodoro(), m.notify("Pomodoro stopped", false)), true
+
+	case key.Matches(msg, m.keymap.skip):
+		return m.advancePomodoro(true), true
+	}
+	return nil, false
+}
+
+// startPomodoro starts a session for the task entered in the pomodoro form.
+func (m *model) startPomodoro(result timer.FormResult) tea.Cmd {
+	session := pomodoro.NewSession(result.Name, result.Tags, m.pomodoroSettings)
+	m.pomodoro = &session
+	return m.notify("Pomodoro started for: "+result.Name, false)
+}
+
+// stopPomodoro ends the running session and records the work done in the
+// current work phase, if any.
+func (m *model) stopPomodoro() tea.Cmd {
+	if m.pomodoro == nil {
+		return nil
+	}
+	session := *m.pomodoro
+	m.pomodoro = nil
+	if worked, ok := session.Stop(); ok {
+		return recordPomodoro(m.db, session, worked)
+	}
+	return nil
+}
+
+// advancePomodoro moves the session on to the next phase when the current
+// one is over, or immediately if skip is true. Every transition is printed
+// above the program with the bell, and finished work is recorded.
+func (m *model) advancePomodoro(skip bool) tea.Cmd {
+	if m.pomodoro == nil {
+		return nil
+	}
+	phase := m.pomodoro.Phase
+	worked, ok := m.pomodoro.Advance(skip)
+	if m.pomodoro.Phase == phase && !ok {
+		return nil
+	}
+
+	message := fmt.Sprintf("%s finished, %s", phase, strings.ToLower(m.pomodoro.Phase.String()))
+	cmds := []tea.Cmd{
+		tea.Println(message + pomodoro.Bell),
+		m.notify(message, false),
+	}
+	if ok {
+		cmds = append(cmds, recordPomodoro(m.db, *m.pomodoro, worked))
+	}
+	return tea.Batch(cmds...)
+}
+
+func (m model) pomodoroView() string {
+	view := m.topBarView()
+
+	s := m.pomodoroSettings
+	if m.pomodoro == nil {
+		view += "No pomodoro running\n"
+		view += m.theme.muted.Render(fmt.Sprintf("Work %s, break %s, long break %s after every %d",
+			util.FormatDuration(s.Work), util.FormatDuration(s.ShortBreak), util.FormatDuration(s.LongBreak), s.Cycles)) + "\n"
+		view += m.helpView()
+		return view
+	}
+
+	p := m.pomodoro
+	view += fmt.Sprintf("%s  %s\n", m.theme.bold.Render(p.Status()), m.theme.running.Render(p.Countdown.View()))
+	view += fmt.Sprintf("%s %s\n", p.Name, m.theme.muted.Render(strings.Join(p.Tags, ", ")))
+	view += fmt.Sprintf("Completed: %d\n", p.Completed)
+	view += m.help.ShortHelpView([]key.Binding{m.keymap.skip, m.keymap.stop}) + "\n"
+
+	view += m.helpView()
+	return view
+}
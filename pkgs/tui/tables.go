@@ -0,0 +1,294 @@
+package tui
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/table"
+
+	"go-time/pkgs/entry"
+	"go-time/pkgs/stopwatch"
+	"go-time/pkgs/tag"
+	"go-time/pkgs/timer"
+	"go-time/pkgs/util"
+)
+
+const (
+	// defaultWidth and defaultHeight are used until the terminal reports its
+	// size.
+	defaultWidth  = 100
+	defaultHeight = 24
+
+	// chromeHeight is the number of lines around a table taken by the top
+	// bar, the table header, the status line and the help.
+	chromeHeight = 9
+
+	// cellPadding is the horizontal padding the table adds to each column.
+	cellPadding  = 2
+	minNameWidth = 10
+)
+
+// tableColumn describes a column of a table. A width of 0 makes the column
+// take whatever width the other columns leave over.
+type tableColumn struct {
+	title string
+	width int
+}
+
+var entryColumns = []tableColumn{
+	{"ID", 5}, {"Name", 0}, {"Start", 19}, {"End", 19}, {"Duration", 8}, {"Tags", 20},
+}
+
+var timerColumns = []tableColumn{
+	{"ID", 5}, {"Name", 0}, {"Start", 19}, {"Elapsed", 9}, {"Tags", 20},
+}
+
+var tagColumns = []tableColumn{
+	{"ID", 5}, {"Name", 0},
+}
+
+// sortState is the column a table is sorted by and the direction.
+type sortState struct {
+	column int
+	desc   bool
+}
+
+// next sorts by the column after the current one, ascending.
+func (s sortState) next(columns int) sortState {
+	return sortState{column: (s.column + 1) % columns}
+}
+
+// layoutColumns sizes columns to fit width and marks the one sorted by.
+func layoutColumns(columns []tableColumn, width int, s sortState) []table.Column {
+	fixed := 0
+	for _, c := range columns {
+		fixed += c.width + cellPadding
+	}
+
+	result := make([]table.Column, len(columns))
+	for i, c := range columns {
+		w := c.width
+		if w == 0 {
+			w = max(width-fixed-cellPadding, minNameWidth)
+		}
+		title := c.title
+		if i == s.column {
+			if s.desc {
+				title += " ▼"
+			} else {
+				title += " ▲"
+			}
+		}
+		result[i] = table.Column{Title: title, Width: w}
+	}
+	return result
+}
+
+// setRows replaces the rows of t, keeping the row at cursor selected.
+func setRows(t *table.Model, rows []table.Row, cursor int) {
+	t.SetRows(rows)
+	t.GotoTop()
+	if cursor > 0 {
+		t.MoveDown(cursor)
+	}
+}
+
+// entryRows lays out entries as table rows, with a * before the IDs of the
+// marked ones.
+func entryRows(entries []entry.Entry, marked map[int]bool) []table.Row {
+	rows := make([]table.Row, len(entries))
+	for i, e := range entries {
+		id := strconv.Itoa(e.ID)
+		if marked[e.ID] {
+			id = "*" + id
+		}
+		rows[i] = table.Row{
+			id,
+			e.Name,
+			util.FormatTime(e.StartTime),
+			util.FormatTime(e.EndTime),
+			util.FormatDuration(e.Duration()),
+			strings.Join(tag.Names(e.Tags), ", "),
+		}
+	}
+	return rows
+}
+
+func timerRows(timers []timer.Timer, watches map[int]stopwatch.Model) []table.Row {
+	rows := make([]table.Row, len(timers))
+	for i, t := range timers {
+		rows[i] = table.Row{
+			strconv.Itoa(t.ID),
+			t.Name,
+			util.FormatTime(t.StartTime),
+			watches[t.ID].View(),
+			strings.Join(t.Tags, ", "),
+		}
+	}
+	return rows
+}
+
+func tagRows(tags []tag.Tag) []table.Row {
+	rows := make([]table.Row, len(tags))
+	for i, t := range tags {
+		rows[i] = table.Row{strconv.Itoa(t.ID), t.Name}
+	}
+	return rows
+}
+
+// less orders a before b, or b before a when the sort is descending.
+func less[T any](a, b T, desc bool, cmp func(a, b T) bool) bool {
+	if desc {
+		return cmp(b, a)
+	}
+	return cmp(a, b)
+}
+
+func sortEntries(entries []entry.Entry, s sortState) {
+	sort.SliceStable(entries, func(i, j int) bool {
+		return less(entries[i], entries[j], s.desc, func(a, b entry.Entry) bool {
+			switch s.column {
+			case 1:
+				return strings.ToLower(a.Name) < strings.ToLower(b.Name)
+			case 2:
+				return a.StartTime.Before(b.StartTime)
+			case 3:
+				return a.EndTime.Before(b.EndTime)
+			case 4:
+				return a.Duration() < b.Duration()
+			case 5:
+				return strings.Join(tag.Names(a.Tags), ",") < strings.Join(tag.Names(b.Tags), ",")
+			}
+			return a.ID < b.ID
+		})
+	})
+}
+
+func sortTimers(timers []timer.Timer, s sortState) {
+	sort.SliceStable(timers, func(i, j int) bool {
+		return less(timers[i], timers[j], s.desc, func(a, b timer.Timer) bool {
+			switch s.column {
+			case 1:
+				return strings.ToLower(a.Name) < strings.ToLower(b.Name)
+			case 2:
+				return a.StartTime.Before(b.StartTime)
+			case 3:
+				return a.StartTime.After(b.StartTime)
+			case 4:
+				return strings.Join(a.Tags, ",") < strings.Join(b.Tags, ",")
+			}
+			return a.ID < b.ID
+		})
+	})
+}
+
+func sortTags(tags []tag.Tag, s sortState) {
+	sort.SliceStable(tags, func(i, j int) bool {
+		return less(tags[i], tags[j], s.desc, func(a, b tag.Tag) bool {
+			if s.column == 1 {
+				return strings.ToLower(a.Name) < strings.ToLower(b.Name)
+			}
+			return a.ID < b.ID
+		})
+	})
+}
+
+// selection holds the IDs of the records selected in each table.
+type selection struct {
+	entry, timer, tag int
+}
+
+func (m *model) selection() selection {
+	return selection{m.selectedEntryID(), m.selectedTimerID(), m.selectedTagID()}
+}
+
+// refreshTables sorts the loaded records and lays them out in the tables for
+// the current window size, keeping the records in sel selected if they are
+// still there.
+func (m *model) refreshTables(sel selection) {
+	width, height := m.width, m.height
+	if width == 0 {
+		width, height = defaultWidth, defaultHeight
+	}
+	tableHeight := max(height-chromeHeight, 1)
+
+	sortEntries(m.entries, m.entriesSort)
+	m.entriesTable.SetColumns(layoutColumns(entryColumns, width, m.entriesSort))
+	m.entriesTable.SetHeight(tableHeight)
+	setRows(&m.entriesTable, entryRows(m.entries, m.marked), indexOf(len(m.entries), func(i int) bool {
+		return m.entries[i].ID == sel.entry
+	}))
+
+	sortTimers(m.timers, m.timersSort)
+	m.timersTable.SetColumns(layoutColumns(timerColumns, width, m.timersSort))
+	m.timersTable.SetHeight(tableHeight)
+	setRows(&m.timersTable, timerRows(m.timers, m.watches), indexOf(len(m.timers), func(i int) bool {
+		return m.timers[i].ID == sel.timer
+	}))
+
+	sortTags(m.tags, m.tagsSort)
+	m.tagsTable.SetColumns(layoutColumns(tagColumns, width, m.tagsSort))
+	m.tagsTable.SetHeight(tableHeight)
+	setRows(&m.tagsTable, tagRows(m.tags), indexOf(len(m.tags), func(i int) bool {
+		return m.tags[i].ID == sel.tag
+	}))
+}
+
+// indexOf returns the first index below n for which match is true, or 0.
+func indexOf(n int, match func(int) bool) int {
+	for i := 0; i < n; i++ {
+		if match(i) {
+			return i
+		}
+	}
+	return 0
+}
+
+func (m *model) selectedEntryID() int {
+	if c := m.entriesTable.Cursor(); c >= 0 && c < len(m.entries) {
+		return m.entries[c].ID
+	}
+	return 0
+}
+
+func (m *model) selectedTimerID() int {
+	if c := m.timersTable.Cursor(); c >= 0 && c < len(m.timers) {
+		return m.timers[c].ID
+	}
+	return 0
+}
+
+func (m *model) selectedTagID() int {
+	if c := m.tagsTable.Cursor(); c >= 0 && c < len(m.tags) {
+		return m.tags[c].ID
+	}
+	return 0
+}
+
+// activeTable returns the table of the current view, or nil if it has none.
+func (m *model) activeTable() *table.Model {
+	switch m.currentView {
+	case "entries":
+		return &m.entriesTable
+	case "timers", "timer":
+		return &m.timersTable
+	case "tags":
+		return &m.tagsTable
+	}
+	return nil
+}
+
+// activeSort returns the sort state of the current view together with its
+// number of columns.
+func (m *model) activeSort() (*sortState, int) {
+	switch m.currentView {
+	case "entries":
+		return &m.entriesSort, len(entryColumns)
+	case "timers", "timer":
+		return &m.timersSort, len(timerColumns)
+	case "tags":
+		return &m.tagsSort, len(tagColumns)
+	}
+	return nil, 0
+}
@@ -0,0 +1,128 @@
+package tui
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/table"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// themes are the built-in color themes. Each maps the color names that can
+// be overridden in the config file to ANSI color numbers or hex colors.
+var themes = map[string]map[string]string{
+	"dark": {
+		"muted":         "243",
+		"cursor":        "212",
+		"header":        "255",
+		"running":       "42",
+		"tab":           "212",
+		"bar":           "212",
+		"key":           "248",
+		"block":         "24",
+		"block_text":    "255",
+		"selected":      "212",
+		"selected_text": "0",
+		"overlap":       "160",
+	},
+	"light": {
+		"muted":         "245",
+		"cursor":        "161",
+		"header":        "232",
+		"running":       "28",
+		"tab":           "161",
+		"bar":           "61",
+		"key":           "240",
+		"block":         "153",
+		"block_text":    "232",
+		"selected":      "161",
+		"selected_text": "255",
+		"overlap":       "203",
+	},
+	"high-contrast": {
+		"muted":         "7",
+		"cursor":        "11",
+		"header":        "15",
+		"running":       "10",
+		"tab":           "11",
+		"bar":           "14",
+		"key":           "15",
+		"block":         "4",
+		"block_text":    "15",
+		"selected":      "11",
+		"selected_text": "0",
+		"overlap":       "9",
+	},
+}
+
+var colorPattern = regexp.MustCompile(`^(#[0-9a-fA-F]{6}|[0-9]{1,3})$`)
+
+// theme holds the styles used to render the TUI.
+type theme struct {
+	muted, bold, heading     lipgloss.Style
+	cursor, header, running  lipgloss.Style
+	tab, bar, helpKey        lipgloss.Style
+	block, selected, overlap lipgloss.Style
+}
+
+// newTheme builds the named built-in theme with the given colors replacing
+// its own.
+func newTheme(name string, overrides map[string]string) (theme, error) {
+	base, ok := themes[name]
+	if !ok {
+		return theme{}, fmt.Errorf("unknown theme %q", name)
+	}
+
+	colors := make(map[string]lipgloss.Color, len(base))
+	for k, v := range base {
+		colors[k] = lipgloss.Color(v)
+	}
+	for k, v := range overrides {
+		if _, ok := base[k]; !ok {
+			return theme{}, fmt.Errorf("unknown color %q, expected one of %s", k, strings.Join(colorNames(), ", "))
+		}
+		if !colorPattern.MatchString(v) {
+			return theme{}, fmt.Errorf("invalid color %q for %s, expected a color number or #rrggbb", v, k)
+		}
+		colors[k] = lipgloss.Color(v)
+	}
+
+	return theme{
+		muted:    lipgloss.NewStyle().Foreground(colors["muted"]),
+		bold:     lipgloss.NewStyle().Bold(true).Foreground(colors["header"]),
+		heading:  lipgloss.NewStyle().Bold(true).Foreground(colors["header"]).MarginTop(1),
+		cursor:   lipgloss.NewStyle().Bold(true).Foreground(colors["cursor"]),
+		header:   lipgloss.NewStyle().Bold(true).Foreground(colors["header"]),
+		running:  lipgloss.NewStyle().Foreground(colors["running"]),
+		tab:      lipgloss.NewStyle().Bold(true).Foreground(colors["tab"]),
+		bar:      lipgloss.NewStyle().Foreground(colors["bar"]),
+		helpKey:  lipgloss.NewStyle().Foreground(colors["key"]),
+		block:    lipgloss.NewStyle().Background(colors["block"]).Foreground(colors["block_text"]),
+		selected: lipgloss.NewStyle().Background(colors["selected"]).Foreground(colors["selected_text"]),
+		overlap:  lipgloss.NewStyle().Background(colors["overlap"]).Foreground(colors["block_text"]),
+	}, nil
+}
+
+func colorNames() []string {
+	names := make([]string, 0, len(themes["dark"]))
+	for name := range themes["dark"] {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// newTable creates a table styled with t.
+func newTable(t theme) table.Model {
+	return table.New(
+		table.WithFocused(true),
+		table.WithHeight(defaultHeight-chromeHeight),
+		table.WithStyles(table.Styles{
+			Header:   t.header.Padding(0, 1),
+			Cell:     lipgloss.NewStyle().Padding(0, 1),
+			Selected: t.cursor,
+		}),
+	)
+}
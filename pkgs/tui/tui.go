@@ -1,16 +1,20 @@
 package tui
 
 import (
-	"context"
 	"database/sql"
 	"fmt"
 	"github.com/charmbracelet/bubbles/help"
 	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/table"
+	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/huh"
-	"github.com/charmbracelet/huh/spinner"
+	"io"
+	"log"
 
+	"go-time/pkgs/config"
 	"go-time/pkgs/entry"
+	"go-time/pkgs/pomodoro"
 	"go-time/pkgs/tag"
 	"go-time/pkgs/timer"
 
@@ -21,23 +25,67 @@ import (
 )
 
 type model struct {
-	db            *sql.DB
-	currentView   string
-	entries       []entry.Entry
-	timers        []timer.Timer
-	tags          []tag.Tag
-	keymap        keymap
-	help          help.Model
-	entriesCursor int
-	timersCursor  int
-	tagsCursor    int
-	menuCursor    int
-	stopwatch     stopwatch.Model
-	form          *huh.Form
-	formActive    bool
+	db           *sql.DB
+	currentView  string
+	entries      []entry.Entry
+	timers       []timer.Timer
+	tags         []tag.Tag
+	keymap       keymap
+	theme        theme
+	help         help.Model
+	entriesTable table.Model
+	timersTable  table.Model
+	tagsTable    table.Model
+	entriesSort  sortState
+	timersSort   sortState
+	tagsSort     sortState
+	width        int
+	height       int
+	menuCursor   int
+	form         *huh.Form
+	formActive   bool
+	formErr      string
+	formKind     string
+	toast        toast
+	confirmation *confirmation
+	loading      bool
+	err          error
+	// editID is the ID of the record being edited by the active form, or 0
+	// when the form creates a new record.
+	editID int
+	// loaded holds all records as loaded from the database, before the
+	// filter is applied to them.
+	loaded      dataMsg
+	filter      filter
+	filterInput textinput.Model
+	filtering   bool
+	// watches count up the running time of each timer, by timer ID.
+	watches map[int]stopwatch.Model
+	// calendarDay is midnight of the day selected on the calendar, and
+	// calendarIndex the entry selected on that day.
+	calendarDay   time.Time
+	calendarIndex int
+	// marked holds the IDs of the entries marked for merging.
+	marked map[int]bool
+	// pomodoro is the running pomodoro session, if any.
+	pomodoro         *pomodoro.Session
+	pomodoroSettings pomodoro.Settings
+	// idleThreshold is how long no heartbeat must be seen for a running
+	// timer to count as idle, and lastBeat when the last heartbeat was
+	// sent. idlePrompts are the questions about idle time still to ask.
+	idleThreshold time.Duration
+	lastBeat      time.Time
+	idlePrompts   []idlePrompt
+	// maxTimer is how long a timer may run before it counts as forgotten.
+	// Forgotten timers found at launch are queued in recovering to ask for
+	// their real end times.
+	maxTimer   time.Duration
+	launched   bool
+	recovering []timer.Timer
 }
 
-func Main(db *sql.DB) {
+// Main runs the TUI with the key bindings and theme of settings.
+func Main(db *sql.DB, settings config.AppConfig) error {
 	if len(os.Getenv("DEBUG")) > 0 {
 		f, err := tea.LogToFile("debug.log", "debug")
 		if err != nil {
@@ -50,314 +98,507 @@ func Main(db *sql.DB) {
 
 			}
 		}(f)
+	} else {
+		// Anything logged while the TUI owns the screen would corrupt it.
+		// Errors are shown as toasts instead.
+		log.SetOutput(io.Discard)
+		defer log.SetOutput(os.Stderr)
 	}
-	p := tea.NewProgram(initialModel(db))
+	m, err := initialModel(db, settings)
+	if err != nil {
+		return err
+	}
+	p := tea.NewProgram(m)
 	if _, err := p.Run(); err != nil {
 		fmt.Printf("Alas, there's been an error: %v", err)
 		os.Exit(1)
 	}
+	return nil
 }
 
+// Update records a heartbeat on user input before handling msg, so that
+// idle time is found before the key acts on a timer.
 func (m *model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
-	var cmds []tea.Cmd
-
-	err := m.updateTimers()
-	if err != nil {
-		fmt.Println("Error: ", err)
-	}
-	err = m.updateEntries()
-	if err != nil {
-		fmt.Println("Error: ", err)
-	}
-	err = m.updateTags()
-	if err != nil {
-		fmt.Println("Error: ", err)
+	beat := m.heartbeat(msg)
+	_, cmd := m.update(msg)
+	if beat == nil {
+		return m, cmd
 	}
+	return m, tea.Sequence(beat, cmd)
+}
 
-	if m.formActive {
+func (m *model) update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	var cmds []tea.Cmd
 
-		form, cmd := m.form.Update(msg)
-		if f, ok := form.(*huh.Form); ok {
-			m.form = f
-			cmds = append(cmds, cmd)
+	switch msg := msg.(type) {
+	case dataMsg:
+		m.loading = false
+		m.err = msg.err
+		if msg.err == nil {
+			m.setData(msg)
 		}
-		if m.form.State == huh.StateCompleted {
-			name := m.form.GetString("name")
-
-			tags := m.form.Get("tags")
-
-			// Convert tags to []string
-			tagsParsed, ok := tags.([]string)
-			if !ok {
-				fmt.Println("Error: tags is not of type []string")
+		if msg.err == nil && !m.launched {
+			m.launched = true
+			for _, t := range m.loaded.timers {
+				if t.Overdue(time.Now(), m.maxTimer) {
+					m.recovering = append(m.recovering, t)
+				}
 			}
+			return m, m.nextRecovery()
+		}
+		return m, nil
 
-			switch m.currentView {
-
-			case "entries":
+	case stopwatch.SharedTickMsg:
+		return m, tea.Batch(m.tickWatches(msg), m.advancePomodoro(false))
 
-				if err != nil {
-					fmt.Println("Error: ", err)
-				}
+	case tea.WindowSizeMsg:
+		m.width, m.height = msg.Width, msg.Height
+		m.refreshTables(m.selection())
+		if m.formActive {
+			break
+		}
+		return m, nil
 
-				//tx, err := m.db.Begin()
-				//entry.CreateEntry(context.Background(), tx, name, startTimeParsed, endTimeParsed, tagsParsed)
-				if err != nil {
-					fmt.Println("Error: ", err)
-				}
+	case refreshMsg:
+		return m, tea.Batch(m.load(), refreshTick())
 
-				m.formActive = false
+	case mutationMsg:
+		if msg.err != nil && msg.retry != nil {
+			m.form = msg.retry
+			cmd := m.openForm(msg.retryKind, msg.retryID)
+			m.formErr = msg.err.Error()
+			return m, cmd
+		}
+		if msg.err != nil {
+			return m, m.notifyErr(msg.err)
+		}
+		for _, id := range msg.unmark {
+			delete(m.marked, id)
+		}
+		return m, tea.Batch(m.notify(msg.status, false), m.load())
 
-			case "timers":
+	case toastExpiredMsg:
+		if msg.id == m.toast.id {
+			m.toast = toast{}
+		}
+		return m, nil
 
-				if err != nil {
-					fmt.Println("Error: ", err)
-				}
-				action := func() {
-					err := timer.CreateTimer(context.Background(), m.db, name, tagsParsed)
-					if err != nil {
-						fmt.Println("Error: ", err)
-					}
-					time.Sleep(1 * time.Second)
-				}
-				err := spinner.New().
-					Title("Creating timer...").
-					Action(action).
-					Run()
-				if err != nil {
-					fmt.Println("Error: ", err)
-				}
+	case heartbeatMsg:
+		if msg.err != nil {
+			return m, m.notifyErr(msg.err)
+		}
+		for _, idle := range msg.idle {
+			m.promptIdle(idlePrompt{timer: timer.Timer{ID: idle.TimerID, Name: idle.Name}, idle: []timer.Idle{idle}})
+		}
+		return m, nil
 
-				m.formActive = false
+	case idleMsg:
+		m.promptIdle(msg.prompt)
+		return m, nil
 
-			case "tags":
-				err := tag.CreateTag(context.Background(), m.db, name)
-				if err != nil {
-					fmt.Println("Error: ", err)
-				}
+	case editFormMsg:
+		if msg.err != nil {
+			return m, m.notifyErr(msg.err)
+		}
+		m.form = msg.form
+		return m, m.openForm(msg.kind, msg.id)
+	}
 
-				m.formActive = false
+	if m.confirmation != nil {
+		return m, m.updateConfirm(msg)
+	}
+	if len(m.idlePrompts) > 0 {
+		return m, m.updateIdle(msg)
+	}
+	if m.formActive {
 
-			}
+		form, cmd := m.form.Update(msg)
+		if f, ok := form.(*huh.Form); ok {
+			m.form = f
+			cmds = append(cmds, cmd)
+		}
+		if m.form.State == huh.StateCompleted {
+			cmds = append(cmds, m.submitForm())
 		} else {
 			switch msg := msg.(type) {
 			case tea.KeyMsg:
 				if msg.Type == tea.KeyEsc {
-					m.form = tag.Form()
-					m.formActive = false
+					kind := m.formKind
+					m.closeForm()
+					if kind == "recover" {
+						cmds = append(cmds, m.nextRecovery())
+					}
 				}
 			}
 		}
 
 		return m, tea.Batch(cmds...)
 	}
+	if m.filtering {
+		return m, m.updateFilter(msg)
+	}
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
+		if m.currentView == "calendar" {
+			if cmd, ok := m.updateCalendar(msg); ok {
+				return m, cmd
+			}
+		}
+		if m.currentView == "entries" {
+			if cmd, ok := m.updateEntries(msg); ok {
+				return m, cmd
+			}
+		}
+		if m.currentView == "pomodoro" {
+			if cmd, ok := m.updatePomodoro(msg); ok {
+				return m, cmd
+			}
+		}
 		switch {
+		case key.Matches(msg, m.keymap.filter):
+			m.filtering = true
+			return m, m.filterInput.Focus()
+
+		case msg.Type == tea.KeyEsc && m.filter.active():
+			m.filterInput.Reset()
+			m.applyFilter()
+			return m, nil
+
 		case key.Matches(msg, m.keymap.add):
-			tags, err := tag.GetTags(context.Background(), m.db)
-			if err != nil {
-				fmt.Println("Error: ", err)
-			}
-			tagsStr := util.Map(tags, func(tag tag.Tag) string {
-				return tag.Name
-			})
+			tagsStr := m.tagNames()
 			switch m.currentView {
 			case "entries":
 				m.form = entry.Form(tagsStr)
-
-				m.formActive = true
-			case "timers":
-				m.form = timer.Form(tagsStr)
-
-				m.formActive = true
+				return m, m.openForm("entries", 0)
+			case "timers", "timer":
+				m.form = m.timerForm()
+				return m, m.openForm("timers", 0)
 			case "tags":
 				m.form = tag.Form()
-
-				m.formActive = true
+				return m, m.openForm("tags", 0)
 			}
-			return m, nil
 
-		case key.Matches(msg, m.keymap.edit):
-			tagsStr, err := tag.GetTagsAsStrArr(context.Background(), m.db)
-			if err != nil {
-				fmt.Println("Error: ", err)
+		case key.Matches(msg, m.keymap.start):
+			if m.currentView == "entries" && len(m.entries) > 0 {
+				return m, restartEntry(m.db, m.entries[m.entriesTable.Cursor()].ID)
 			}
-			switch m.currentView {
-			case "entries":
-				e := m.entries[m.entriesCursor]
-				m.form = entry.EditForm(e, tagsStr)
-				m.formActive = true
+			m.form = m.timerForm()
+			return m, m.openForm("timers", 0)
 
-			case "timers":
-				t := m.timers[m.timersCursor]
-				m.form = timer.EditForm(t, tagsStr)
-				m.formActive = true
+		case key.Matches(msg, m.keymap.stop):
+			if (m.currentView == "timers" || m.currentView == "timer") && len(m.timers) > 0 {
+				return m, stopTimer(m.db, m.timers[m.timersTable.Cursor()], m.idleThreshold)
+			}
 
-			case "tags":
-				t := m.tags[m.tagsCursor]
-				m.form = tag.EditForm(t)
-				m.formActive = true
+		case key.Matches(msg, m.keymap.lap):
+			if (m.currentView == "timers" || m.currentView == "timer") && len(m.timers) > 0 {
+				m.form = timer.LapForm()
+				return m, m.openForm("lap", m.timers[m.timersTable.Cursor()].ID)
 			}
 
-		case key.Matches(msg, m.keymap.delete):
+		case key.Matches(msg, m.keymap.edit):
+			tagsStr := m.tagNames()
 			switch m.currentView {
 			case "entries":
-				e := m.entries[m.entriesCursor]
-				err := entry.DeleteEntry(context.Background(), m.db, e.ID)
-				if err != nil {
-					fmt.Println("Error: ", err)
+				if len(m.entries) > 0 {
+					return m, loadEntryForm(m.db, m.entries[m.entriesTable.Cursor()].ID, tagsStr)
 				}
-			case "timers":
-				t := m.timers[m.timersCursor]
-				err := timer.DeleteTimer(context.Background(), m.db, t.ID)
 
-				if err != nil {
-					fmt.Println("Error: ", err)
+			case "timers", "timer":
+				if len(m.timers) > 0 {
+					return m, loadTimerForm(m.db, m.timers[m.timersTable.Cursor()].ID, tagsStr)
 				}
 
 			case "tags":
-				t := m.tags[m.tagsCursor]
-				err := tag.DeleteTag(context.Background(), m.db, t.ID)
-				if err != nil {
-					fmt.Println("Error: ", err)
+				if len(m.tags) > 0 {
+					t := m.tags[m.tagsTable.Cursor()]
+					m.form = tag.EditForm(t)
+					return m, m.openForm("tags", t.ID)
 				}
 			}
 
-		case key.Matches(msg, m.keymap.up):
+		case key.Matches(msg, m.keymap.delete):
 			switch m.currentView {
 			case "entries":
-				if m.entriesCursor > 0 {
-					m.entriesCursor--
+				if len(m.entries) > 0 {
+					e := m.entries[m.entriesTable.Cursor()]
+					m.confirm(fmt.Sprintf("Delete entry %d %q?", e.ID, e.Name),
+						deleteRecord(m.db, "entries", e.ID, fmt.Sprintf("entry %q", e.Name)))
 				}
 			case "timers", "timer":
-				if m.timersCursor > 0 {
-					m.timersCursor--
-					cmd := m.startStopwatch(m.timers[m.timersCursor])
-					return m, cmd
+				if len(m.timers) > 0 {
+					t := m.timers[m.timersTable.Cursor()]
+					m.confirm(fmt.Sprintf("Delete running timer %q without saving an entry?", t.Name),
+						deleteRecord(m.db, "timers", t.ID, fmt.Sprintf("timer %q", t.Name)))
 				}
 			case "tags":
-				if m.tagsCursor > 0 {
-					m.tagsCursor--
+				if len(m.tags) > 0 {
+					t := m.tags[m.tagsTable.Cursor()]
+					m.confirm(fmt.Sprintf("Delete tag %q from all entries and timers?", t.Name),
+						deleteRecord(m.db, "tags", t.ID, fmt.Sprintf("tag %q", t.Name)))
 				}
 			}
+			return m, nil
+
+		case key.Matches(msg, m.keymap.up):
+			m.moveCursor(-1)
+			return m, nil
 
 		case key.Matches(msg, m.keymap.down):
-			switch m.currentView {
-			case "entries":
-				if m.entriesCursor < len(m.entries)-1 {
-					m.entriesCursor++
-				}
-			case "timers", "timer":
-				if m.timersCursor < len(m.timers)-1 {
-					m.timersCursor++
-					cmd := m.startStopwatch(m.timers[m.timersCursor])
-					return m, cmd
-				}
-			case "tags":
-				if m.tagsCursor < len(m.tags)-1 {
-					m.tagsCursor++
-				}
+			m.moveCursor(1)
+			return m, nil
+
+		case key.Matches(msg, m.keymap.pageUp):
+			m.moveCursor(-m.timersTable.Height())
+			return m, nil
+
+		case key.Matches(msg, m.keymap.pageDown):
+			m.moveCursor(m.timersTable.Height())
+			return m, nil
+
+		case key.Matches(msg, m.keymap.sort):
+			if s, columns := m.activeSort(); s != nil {
+				*s = s.next(columns)
+				m.refreshTables(selection{})
 			}
 
-		case key.Matches(msg, m.keymap.left):
+		case key.Matches(msg, m.keymap.reverse):
+			if s, _ := m.activeSort(); s != nil {
+				s.desc = !s.desc
+				m.refreshTables(selection{})
+			}
+
+		case key.Matches(msg, m.keymap.left), key.Matches(msg, m.keymap.prevTab):
 			m.navigateMenu(-1)
 			return m, nil
 
-		case key.Matches(msg, m.keymap.right):
+		case key.Matches(msg, m.keymap.right), key.Matches(msg, m.keymap.nextTab):
 			m.navigateMenu(1)
-			if m.currentView == "timer" && len(m.timers) > 0 {
-				cmd := m.startStopwatch(m.timers[m.timersCursor])
-				return m, cmd
-			}
 			return m, nil
 
 		case key.Matches(msg, m.keymap.quit):
-			return m, tea.Quit
+			return m, tea.Sequence(m.stopPomodoro(), tea.Quit)
 		}
 	}
-	var cmd tea.Cmd
-	m.stopwatch, cmd = m.stopwatch.Update(msg)
-	return m, cmd
+	return m, nil
 }
 
 func (m *model) View() string {
 	var s string
-	var err error
+	if len(m.idlePrompts) > 0 {
+		return m.idleView()
+	}
 	if m.formActive {
-		m.form.Init()
-		return m.form.View()
+		s = m.form.View()
+		if m.formErr != "" {
+			s += "\nError: " + m.formErr + "\n"
+		}
+		return s
+	}
+	if m.confirmation != nil {
+		return m.confirmView()
 	}
 	switch m.currentView {
+	case "dashboard":
+		s += m.dashboardView()
+	case "entries":
+		s += m.entriesView()
+	case "calendar":
+		s += m.calendarView()
+	case "timers":
+		s += m.timersView()
+	case "tags":
+		s += m.tagsView()
+	case "timer":
+		s += m.timerView()
+	case "pomodoro":
+		s += m.pomodoroView()
+	}
+
+	return s
+}
+
+// openForm activates m.form for the record of the given kind ("entries",
+// "timers" or "tags") with the given ID, or for a new record if id is 0. The
+// "lap" kind marks a lap on the timer with the given ID, the "split" kind
+// splits the entry with the given ID at the time entered, the "recover" kind
+// stops the forgotten timer with the given ID at the time entered, and the
+// "pomodoro" kind starts a pomodoro session instead of saving a record.
+func (m *model) openForm(kind string, id int) tea.Cmd {
+	m.formKind = kind
+	m.editID = id
+	m.formErr = ""
+	m.formActive = true
+	return m.form.Init()
+}
+
+func (m *model) closeForm() {
+	m.form = tag.Form()
+	m.formActive = false
+	m.formErr = ""
+	m.formKind = ""
+	m.editID = 0
+}
+
+// submitForm reads the values of the completed form and returns a command
+// that saves them, creating a record or updating the one being edited in
+// place. The form is closed while saving and reopened if saving fails.
+func (m *model) submitForm() tea.Cmd {
+	kind, id, tagsStr := m.formKind, m.editID, m.tagNames()
+
+	var cmd tea.Cmd
+	switch kind {
 	case "entries":
-		err = m.updateEntries()
+		result, err := entry.ReadForm(m.form)
 		if err != nil {
-			s += "Error: " + err.Error()
-		} else {
-			s += m.entriesView()
+			m.form = entry.ResultForm(result, tagsStr)
+			m.formErr = err.Error()
+			return m.form.Init()
 		}
+		cmd = saveEntry(m.db, id, result, tagsStr)
+
 	case "timers":
-		err = m.updateTimers()
+		result, err := timer.ReadForm(m.form)
 		if err != nil {
-			s += "Error: " + err.Error()
-		} else {
-			s += m.timersView()
+			m.formErr = err.Error()
+			m.form = m.timerForm()
+			return m.form.Init()
 		}
+		cmd = saveTimer(m.db, id, result, tagsStr, m.loaded.templates)
+
 	case "tags":
-		err = m.updateTags()
+		cmd = saveTag(m.db, id, m.form.GetString("name"))
+
+	case "lap":
+		for _, t := range m.timers {
+			if t.ID == id {
+				cmd = addLap(m.db, t, m.form.GetString("note"))
+			}
+		}
+
+	case "split":
+		for _, e := range m.loaded.entries {
+			if e.ID != id {
+				continue
+			}
+			at, err := entry.ReadSplitForm(m.form, e)
+			if err != nil {
+				m.formErr = err.Error()
+				m.form = entry.SplitForm(e)
+				return m.form.Init()
+			}
+			cmd = splitEntry(m.db, e, at)
+		}
+
+	case "recover":
+		for _, t := range m.loaded.timers {
+			if t.ID != id {
+				continue
+			}
+			end, err := timer.ReadRecoveryForm(m.form, t)
+			if err != nil {
+				m.formErr = err.Error()
+				m.form = timer.RecoveryForm(t, m.maxTimer)
+				return m.form.Init()
+			}
+			cmd = stopTimerAt(m.db, t, end)
+		}
+		m.closeForm()
+		return tea.Batch(cmd, m.notify("Saving...", false), m.nextRecovery())
+
+	case "pomodoro":
+		result, err := timer.ReadForm(m.form)
 		if err != nil {
-			s += "Error: " + err.Error()
-		} else {
-			s += m.tagsView()
+			m.formErr = err.Error()
+			m.form = m.timerForm()
+			return m.form.Init()
 		}
-	case "timer":
-		s += m.timerView()
+		m.closeForm()
+		return m.startPomodoro(result)
 	}
 
-	return s
+	m.closeForm()
+	return tea.Batch(cmd, m.notify("Saving...", false))
 }
 
-func (m *model) updateEntries() error {
-	ctx := context.Background()
-	entries, err := entry.ReadEntries(ctx, m.db)
-	if err != nil {
-		return err
-	}
-	m.entries = entries
-	return nil
+// load starts loading the data from the database in the background.
+func (m *model) load() tea.Cmd {
+	m.loading = true
+	return loadData(m.db)
 }
 
-func (m *model) updateTimers() error {
-	ctx := context.Background()
-	timers, err := timer.ReadTimers(ctx, m.db)
-	if err != nil {
-		return err
-	}
-	m.timers = timers
-	return nil
+func (m *model) setData(msg dataMsg) {
+	m.loaded = msg
+	m.syncWatches()
+	m.applyFilter()
 }
 
-func (m *model) updateTags() error {
-	ctx := context.Background()
-	tags, err := tag.GetTags(ctx, m.db)
-	if err != nil {
-		return err
-	}
-	m.tags = tags
-	return nil
+// timerForm returns the form for a new timer, offering the loaded
+// templates.
+func (m *model) timerForm() *huh.Form {
+	return timer.Form(m.tagNames(), m.loaded.templates)
 }
 
-func (m *model) startStopwatch(timer timer.Timer) tea.Cmd {
-	startTime := timer.StartTime
-	elapsedTime := time.Since(startTime)
-	m.stopwatch = stopwatch.New()
-	m.stopwatch = m.stopwatch.SetElapsedTime(elapsedTime)
-	cmd := m.stopwatch.Start()
+// tagNames returns the names of all tags, whether the filter shows them or
+// not, for use as form options.
+func (m *model) tagNames() []string {
+	return tag.Names(m.loaded.tags)
+}
+
+// updateFilter passes msg to the filter input while it is being edited,
+// narrowing the records as the user types. Enter keeps the filter and
+// returns to the table, Esc clears it.
+func (m *model) updateFilter(msg tea.Msg) tea.Cmd {
+	if msg, ok := msg.(tea.KeyMsg); ok {
+		switch msg.Type {
+		case tea.KeyEnter:
+			m.filtering = false
+			m.filterInput.Blur()
+			return nil
+		case tea.KeyEsc:
+			m.filtering = false
+			m.filterInput.Blur()
+			m.filterInput.Reset()
+			m.applyFilter()
+			return nil
+		}
+	}
+
+	var cmd tea.Cmd
+	value := m.filterInput.Value()
+	m.filterInput, cmd = m.filterInput.Update(msg)
+	if m.filterInput.Value() != value {
+		m.applyFilter()
+	}
 	return cmd
 }
 
+// applyFilter narrows the loaded records to those matching the filter input.
+// The same filter applies to every tab.
+func (m *model) applyFilter() {
+	sel := m.selection()
+	now := time.Now()
+	m.filter = parseFilter(m.filterInput.Value(), now)
+	m.entries = m.filter.entries(m.loaded.entries)
+	m.timers = m.filter.timers(m.loaded.timers, now)
+	m.tags = m.filter.tags(m.loaded.tags)
+	m.refreshTables(sel)
+}
+
+// moveCursor moves the selection of the current table by n rows.
+func (m *model) moveCursor(n int) {
+	t := m.activeTable()
+	if t == nil {
+		return
+	}
+	if n < 0 {
+		t.MoveUp(-n)
+	} else {
+		t.MoveDown(n)
+	}
+}
+
 func (m *model) navigateMenu(direction int) {
-	menuItems := []string{"entries", "timers", "timer", "tags"}
 	currentIndex := util.IndexOf(menuItems, m.currentView)
 	if currentIndex != -1 {
 		newIndex := (currentIndex + direction + len(menuItems)) % len(menuItems)
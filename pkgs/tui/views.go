@@ -3,23 +3,67 @@ package tui
 import (
 	"fmt"
 	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/table"
+	"go-time/pkgs/util"
+	"time"
 )
 
+// menuItems are the tabs of the TUI in the order they are shown.
+var menuItems = []string{"dashboard", "entries", "calendar", "timers", "timer", "pomodoro", "tags"}
+
 func (m model) topBarView() string {
 	view := "---------- Go-Time ---------- \n"
-	menuItems := []string{"entries", "timers", "timer", "tags"}
 	for _, item := range menuItems {
 		if m.currentView == item {
-			view += "[" + item + "]"
+			view += m.theme.tab.Render("[" + item + "]")
 		} else {
 			view += " " + item + " "
 		}
 	}
+	return view + "\n" + m.filterView()
+}
+
+// filterView shows the filter input while it is edited, and the active
+// filter otherwise.
+func (m model) filterView() string {
+	var view string
+	switch {
+	case m.filtering:
+		view = m.filterInput.View()
+	case m.filter.active() || m.filter.err != "":
+		view = "Filter: " + m.filterInput.Value() + "  (/ to edit, esc to clear)"
+	default:
+		return ""
+	}
+	if m.filter.err != "" {
+		view += "  " + m.filter.err
+	}
+	return view + "\n"
+}
+
+func (m model) statusView() string {
+	var view string
+	if m.loading {
+		view += "\nLoading..."
+	}
+	if m.err != nil {
+		view += "\nError loading data: " + m.err.Error()
+	}
+	if m.toast.text != "" {
+		text := m.toast.text
+		if m.toast.isErr {
+			text = m.theme.overlap.Render(text)
+		}
+		view += "\n" + text
+	}
+	if view == "" {
+		return ""
+	}
 	return view + "\n"
 }
 
 func (m model) helpView() string {
-	return "\n" + m.help.ShortHelpView([]key.Binding{
+	return m.statusView() + "\n" + m.help.ShortHelpView([]key.Binding{
 		m.keymap.up,
 		m.keymap.down,
 		m.keymap.left,
@@ -29,46 +73,31 @@ func (m model) helpView() string {
 		m.keymap.edit,
 		m.keymap.delete,
 
+		m.keymap.start,
+		m.keymap.stop,
+
+		m.keymap.sort,
+		m.keymap.reverse,
+		m.keymap.pageDown,
+		m.keymap.filter,
+		m.keymap.nextTab,
+
 		m.keymap.quit,
 	})
 }
 
 func (m model) tagsView() string {
 	view := m.topBarView()
-	err := m.updateTags()
-
-	if err != nil {
-		return "Error: " + err.Error()
-	}
-
-	for i, tag := range m.tags {
-		cursor := " "
-		if m.tagsCursor == i {
-			cursor = ">"
-		}
-		view += fmt.Sprintf("%s ID: %d, Name: %s\n", cursor, tag.ID, tag.Name)
-	}
+	view += m.tableView(m.tagsTable, len(m.tags), "No tags")
 	view += m.helpView()
 	return view
 }
 
 func (m model) entriesView() string {
 	view := m.topBarView()
-	err := m.updateEntries()
-	if err != nil {
-		return "Error: " + err.Error()
-	}
-
-	for i, entry := range m.entries {
-		cursor := " "
-		if m.entriesCursor == i {
-			cursor = ">"
-		}
-		line := fmt.Sprintf("%s ID: %d, Name: %s, Start: %s, End: %s",
-			cursor, entry.ID, entry.Name, entry.StartTime.Format("2006-01-02 15:04:05"),
-			entry.EndTime.Format("2006-01-02 15:04:05"))
-
-		view += line + "\n"
+	view += m.tableView(m.entriesTable, len(m.entries), "No entries")
+	if len(m.entries) > 0 {
+		view += m.help.ShortHelpView([]key.Binding{m.keymap.split, m.keymap.mark, m.keymap.merge}) + "\n"
 	}
 	view += m.helpView()
 	return view
@@ -76,35 +105,51 @@ func (m model) entriesView() string {
 
 func (m model) timersView() string {
 	view := m.topBarView()
-	err := m.updateTimers()
-	if err != nil {
-		return "Error: " + err.Error()
-	}
-
-	for i, timer := range m.timers {
-		cursor := " "
-		if m.timersCursor == i {
-			cursor = ">"
-		}
 
-		line := fmt.Sprintf("%s ID: %d, Name: %s, Start: %s",
-			cursor, timer.ID, timer.Name, timer.StartTime.Format("2006-01-02 15:04:05"))
-		view += line + "\n"
-
-	}
+	// Refresh the elapsed times of the running timers on a copy of the
+	// table.
+	t := m.timersTable
+	t.SetRows(timerRows(m.timers, m.watches))
+	view += m.tableView(t, len(m.timers), "No running timers")
 	view += m.helpView()
 	return view
 }
 
+// tableView renders t followed by the position of its cursor, or empty if
+// it has no rows.
+func (m model) tableView(t table.Model, rows int, empty string) string {
+	if rows == 0 && m.filter.active() {
+		return "No matches\n"
+	}
+	if rows == 0 {
+		return empty + "\n"
+	}
+	return fmt.Sprintf("%s\n%d/%d\n", t.View(), t.Cursor()+1, rows)
+}
+
 func (m model) timerView() string {
 	view := m.topBarView()
 
-	timer := m.timers[m.timersCursor]
+	if len(m.timers) == 0 {
+		view += "No running timers\n"
+		view += m.helpView()
+		return view
+	}
+	timer := m.timers[m.timersTable.Cursor()]
 
 	line := fmt.Sprintf("ID: %d, Name: %s, Start: %s",
-		timer.ID, timer.Name, timer.StartTime.Format("2006-01-02 15:04:05"))
+		timer.ID, timer.Name, util.FormatTime(timer.StartTime))
 	view += line + "\n"
-	view += m.stopwatch.View() + "\n"
+	view += m.theme.running.Render(m.watches[timer.ID].View()) + "\n"
+
+	// Each lap is shown with the time from the previous one.
+	var previous time.Duration
+	for i, lap := range m.watches[timer.ID].Laps() {
+		view += fmt.Sprintf("Lap %d  %s  %s\n", i+1,
+			m.theme.muted.Render(util.FormatDuration(lap.Elapsed-previous)), lap.Note)
+		previous = lap.Elapsed
+	}
+	view += m.help.ShortHelpView([]key.Binding{m.keymap.lap}) + "\n"
 
 	view += m.helpView()
 	return view
@@ -0,0 +1,73 @@
+package tui
+
+import (
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"go-time/pkgs/stopwatch"
+	"go-time/pkgs/timer"
+)
+
+// tickInterval is how often the stopwatches of running timers advance.
+const tickInterval = time.Second
+
+// heartbeatInterval is how often at most user input is recorded as a
+// heartbeat of the running timers.
+const heartbeatInterval = time.Minute
+
+// syncWatches keeps a running stopwatch for every loaded timer, set to the
+// time elapsed since the timer was started in case it was edited, and to
+// the laps marked on it.
+// Stopwatches of timers that are no longer running are dropped.
+func (m *model) syncWatches() {
+	watches := make(map[int]stopwatch.Model, len(m.loaded.timers))
+	for _, t := range m.loaded.timers {
+		w, ok := m.watches[t.ID]
+		if !ok {
+			w = stopwatch.NewWithInterval(0, tickInterval).Run()
+		}
+		laps := make([]stopwatch.Lap, len(t.Laps))
+		for i, lap := range t.Laps {
+			laps[i] = stopwatch.Lap{Elapsed: lap.Time.Sub(t.StartTime), Note: lap.Note}
+		}
+		watches[t.ID] = w.SetElapsedTime(time.Since(t.StartTime)).SetLaps(laps)
+	}
+	m.watches = watches
+}
+
+// tickWatches redraws every stopwatch on a shared tick and schedules the
+// next one, so only a single tick is pending however many timers run.
+func (m *model) tickWatches(msg stopwatch.SharedTickMsg) tea.Cmd {
+	for id, w := range m.watches {
+		m.watches[id], _ = w.Update(msg)
+	}
+	return stopwatch.Tick(tickInterval)
+}
+
+// heartbeat returns a command recording a heartbeat if msg is user input
+// and none was recorded within heartbeatInterval.
+func (m *model) heartbeat(msg tea.Msg) tea.Cmd {
+	switch msg.(type) {
+	case tea.KeyMsg, tea.MouseMsg:
+	default:
+		return nil
+	}
+	if time.Since(m.lastBeat) < heartbeatInterval {
+		return nil
+	}
+	m.lastBeat = time.Now()
+	return heartbeat(m.db, m.idleThreshold)
+}
+
+// nextRecovery opens the form asking for the real end time of the next
+// forgotten timer, unless another form is open.
+func (m *model) nextRecovery() tea.Cmd {
+	if m.formActive || len(m.recovering) == 0 {
+		return nil
+	}
+	t := m.recovering[0]
+	m.recovering = m.recovering[1:]
+	m.form = timer.RecoveryForm(t, m.maxTimer)
+	return m.openForm("recover", t.ID)
+}
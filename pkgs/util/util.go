@@ -1,10 +1,18 @@
 package util
 
 import (
+	"fmt"
 	"github.com/charmbracelet/huh"
 	"time"
 )
 
+// TimeLayout is the layout used to display and enter times.
+const TimeLayout = "2006-01-02 15:04:05"
+
+// location is the zone times are displayed in and day boundaries are
+// computed in. It is set from the timezone setting at startup.
+var location = time.Local
+
 func Map[T any, U any](slice []T, f func(T) U) []U {
 	result := make([]U, len(slice))
 	for i, v := range slice {
@@ -34,6 +42,77 @@ func TimePtrToStringPtr(t *time.Time) *string {
 	if t == nil {
 		return nil
 	}
-	s := t.Format("2006-01-02 15:04:05")
+	s := FormatTime(*t)
 	return &s
 }
+
+// LoadLocation resolves a timezone setting. An empty name or "Local" is the
+// system zone.
+func LoadLocation(name string) (*time.Location, error) {
+	if name == "" || name == "Local" {
+		return time.Local, nil
+	}
+	return time.LoadLocation(name)
+}
+
+func SetLocation(loc *time.Location) {
+	location = loc
+}
+
+func Location() *time.Location {
+	return location
+}
+
+// FormatTime formats t in the configured zone.
+func FormatTime(t time.Time) string {
+	return t.In(location).Format(TimeLayout)
+}
+
+// ParseTime parses a time entered by the user in the configured zone.
+func ParseTime(s string) (time.Time, error) {
+	t, err := time.ParseInLocation(TimeLayout, s, location)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid time %q, expected YYYY-MM-DD HH:MM:SS", s)
+	}
+	return t, nil
+}
+
+// ValidateTime reports whether s can be parsed by ParseTime. It is meant to
+// be used as a form field validator.
+func ValidateTime(s string) error {
+	_, err := ParseTime(s)
+	return err
+}
+
+// ValidateNotEmpty is a form field validator for required values.
+func ValidateNotEmpty(s string) error {
+	if s == "" {
+		return fmt.Errorf("cannot be empty")
+	}
+	return nil
+}
+
+// ZoneOffset returns the offset of t from UTC in seconds.
+func ZoneOffset(t time.Time) int {
+	_, offset := t.Zone()
+	return offset
+}
+
+// InOffset returns t in a fixed zone with the given offset from UTC in
+// seconds.
+func InOffset(t time.Time, offset int) time.Time {
+	return t.In(time.FixedZone("", offset))
+}
+
+// StartOfDay returns midnight of the day containing t in the configured
+// zone.
+func StartOfDay(t time.Time) time.Time {
+	t = t.In(location)
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, location)
+}
+
+// FormatDuration formats d as hours and minutes, e.g. 3h05m.
+func FormatDuration(d time.Duration) string {
+	d = d.Round(time.Minute)
+	return fmt.Sprintf("%dh%02dm", d/time.Hour, (d%time.Hour)/time.Minute)
+}